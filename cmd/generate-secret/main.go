@@ -1,21 +1,135 @@
 package main
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
 )
 
 func main() {
+	alg := flag.String("alg", "HS256", "signing algorithm: HS256, RS256, ES256 or EdDSA")
+	kid := flag.String("kid", time.Now().Format("2006-01"), "key ID for asymmetric keys")
+	out := flag.String("out", "keys", "output directory for asymmetric key files")
+	flag.Parse()
+
+	if *alg == "HS256" {
+		generateSecret()
+		return
+	}
+
+	privateKey, err := generateKey(*alg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	privateFile, publicFile, err := writeKeyPair(privateKey, *out, *kid)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("Generated key pair:")
+	fmt.Println(privateFile)
+	fmt.Println(publicFile)
+
+	fmt.Println("\nAdd to config.yaml:")
+	fmt.Printf("auth:\n  signing:\n    algorithm: %s\n    active_kid: \"%s\"\n    keys:\n      - kid: \"%s\"\n        algorithm: %s\n        private_key_file: %s\n        public_key_file: %s\n",
+		*alg, *kid, *kid, *alg, privateFile, publicFile)
+}
+
+func generateSecret() {
 	bytes := make([]byte, 32)
 	if _, err := rand.Read(bytes); err != nil {
 		panic(err)
 	}
-	
+
 	secret := base64.StdEncoding.EncodeToString(bytes)
 	fmt.Println("Generated JWT Secret:")
 	fmt.Println(secret)
-	
+
 	fmt.Println("\nAdd to config.yaml:")
-	fmt.Printf("auth:\n  jwt_secret: \"%s\"\n  token_expiry: 24h\n", secret)
-}
\ No newline at end of file
+	fmt.Printf("auth:\n  jwt_secret: \"%s\"\n  token_expiry: 15m\n  refresh_token_expiry: 720h\n", secret)
+}
+
+func generateKey(alg string) (crypto.Signer, error) {
+	switch alg {
+	case "RS256":
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+func writeKeyPair(key crypto.Signer, dir, kid string) (string, string, error) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", "", err
+	}
+
+	privateFile := filepath.Join(dir, kid+".pem")
+	publicFile := filepath.Join(dir, kid+".pub.pem")
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	if err := writeNewFile(privateFile, privatePEM, 0o600); err != nil {
+		return "", "", err
+	}
+
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	if err := writeNewFile(publicFile, publicPEM, 0o644); err != nil {
+		os.Remove(privateFile)
+		return "", "", err
+	}
+
+	return privateFile, publicFile, nil
+}
+
+// writeNewFile writes data to a file that must not exist yet, so that an
+// existing key is never overwritten.
+func writeNewFile(name string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists, choose another -kid or -out", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(name)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return err
+	}
+
+	return nil
+}
@@ -4,14 +4,18 @@ import (
     "context"
     "fmt"
     "log"
+    "log/slog"
     "net/http"
+    "os"
 
     "github.com/MorozkoArt/go-crud-api/internal/config"
-    "github.com/MorozkoArt/go-crud-api/internal/db"
     "github.com/MorozkoArt/go-crud-api/internal/handlers"
-    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/i18n"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/mailer"
     "github.com/MorozkoArt/go-crud-api/internal/services"
     "github.com/MorozkoArt/go-crud-api/internal/router"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
 )
 
 func main() {
@@ -22,23 +26,61 @@ func main() {
         log.Fatalf("Configuration loading error: %v", err)
     }
 
-    pool, err := db.NewPostgresDB(ctx, cfg)
+    logger, err := logging.New(cfg.Log, os.Stderr)
     if err != nil {
-        log.Fatalf("Error connecting to the database: %v", err)
+        log.Fatalf("Logger initialization error: %v", err)
     }
-    defer pool.Close()
+    slog.SetDefault(logger)
 
-    userRepo := repository.NewUserRepository(pool)
-    authService := services.NewAuthService(cfg.Auth.JWTSecret, cfg.Auth.TokenExpiry)
-    userService := services.NewUserService(userRepo, authService)
-    userHandler := handlers.NewUserHandler(userService)
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        if err := runMigrate(ctx, cfg, os.Args[2:]); err != nil {
+            fatal("migration error", err)
+        }
+        return
+    }
 
-    r := router.NewRouter(userHandler, authService)
+    store, err := openStorage(ctx, cfg)
+    if err != nil {
+        fatal("error connecting to the database", err)
+    }
+    defer store.close()
+
+    jwtService, err := services.NewJWTService(cfg.Auth)
+    if err != nil {
+        fatal("JWT signing keys loading error", err)
+    }
+
+    authService := services.NewAuthService(cfg.Auth, jwtService, store.refreshTokens, store.revocations)
+    loginLimiter := services.NewLoginLimiter(store.loginAttempts, cfg.Auth.Lockout)
+
+    mail, err := mailer.New(cfg.Mail)
+    if err != nil {
+        fatal("mailer initialization error", err)
+    }
+
+    userService := services.NewUserService(store.users, store.userTokens, store.recoveryCodes, store.tx, authService, loginLimiter, mail, cfg.Auth)
+    userHandler := handlers.NewUserHandler(userService, cfg.Server.RequireIfMatch)
+
+    purger := services.NewUserPurger(store.users, cfg.Users)
+    go purger.Run(ctx)
+    authHandler := handlers.NewAuthHandler(authService)
+
+    translator, err := i18n.New(cfg.I18n, utils.Validator())
+    if err != nil {
+        fatal("message catalogs loading error", err)
+    }
+
+    r := router.NewRouter(userHandler, authHandler, authService, translator, logger)
 
     addr := fmt.Sprintf(":%d", cfg.Server.Port)
-    log.Printf("Server starting on %s", addr)
+    slog.Info("server starting", "addr", addr)
     
     if err := http.ListenAndServe(addr, r); err != nil {
-        log.Fatalf("Server failed to start: %v", err)
+        fatal("server failed to start", err)
     }
+}
+
+func fatal(msg string, err error) {
+    slog.Error(msg, "error", err)
+    os.Exit(1)
 }
\ No newline at end of file
@@ -0,0 +1,89 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "text/tabwriter"
+
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+    "github.com/MorozkoArt/go-crud-api/internal/db"
+)
+
+const migrateUsage = "usage: main migrate up|down|status|redo"
+
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+    if len(args) != 1 {
+        return errors.New(migrateUsage)
+    }
+
+    migrator, closeDB, err := openMigrator(ctx, cfg.Database)
+    if err != nil {
+        return err
+    }
+    defer closeDB()
+    defer migrator.Close()
+
+    switch args[0] {
+    case "up":
+        return migrator.Up(ctx)
+    case "down":
+        return migrator.Down(ctx)
+    case "redo":
+        return migrator.Redo(ctx)
+    case "status":
+        statuses, err := migrator.Status(ctx)
+        if err != nil {
+            return err
+        }
+
+        w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+        fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+        for _, s := range statuses {
+            appliedAt := "-"
+            if !s.AppliedAt.IsZero() {
+                appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+            }
+            fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Source.Version, s.State, appliedAt, s.Source.Path)
+        }
+        return w.Flush()
+    }
+
+    return errors.New(migrateUsage)
+}
+
+// openMigrator connects without the schema version check, which is exactly
+// what the migrate subcommand is there to fix.
+func openMigrator(ctx context.Context, cfg config.DatabaseConfig) (*db.Migrator, func(), error) {
+    switch cfg.Driver {
+    case "postgres":
+        pool, err := db.Connect(ctx, cfg)
+        if err != nil {
+            return nil, nil, err
+        }
+
+        migrator, err := db.NewMigrator(pool)
+        if err != nil {
+            pool.Close()
+            return nil, nil, err
+        }
+        return migrator, pool.Close, nil
+    case "sqlite":
+        sqlDB, err := db.OpenSQLite(ctx, cfg)
+        if err != nil {
+            return nil, nil, err
+        }
+
+        migrator, err := db.NewSQLiteMigrator(sqlDB)
+        if err != nil {
+            sqlDB.Close()
+            return nil, nil, err
+        }
+        return migrator, func() { sqlDB.Close() }, nil
+    case "memory":
+        return nil, nil, errors.New("the memory driver has no schema to migrate")
+    }
+
+    return nil, nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+}
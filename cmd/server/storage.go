@@ -0,0 +1,99 @@
+package main
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+    "github.com/MorozkoArt/go-crud-api/internal/db"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+)
+
+// storage holds the repositories backed by the configured database driver.
+type storage struct {
+    users         repository.UserRepository
+    refreshTokens repository.RefreshTokenRepository
+    userTokens    repository.UserTokenRepository
+    recoveryCodes repository.RecoveryCodeRepository
+    revocations   repository.RevocationStore
+    loginAttempts repository.LoginAttemptStore
+    tx            repository.TxManager
+    close         func()
+}
+
+func openStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+    var s *storage
+
+    switch cfg.Database.Driver {
+    case "postgres":
+        isoLevel, err := txIsoLevel(cfg.Database.TxIsolation)
+        if err != nil {
+            return nil, err
+        }
+
+        pool, err := db.NewPostgresDB(ctx, cfg)
+        if err != nil {
+            return nil, err
+        }
+
+        s = &storage{
+            users:         repository.NewUserRepository(pool),
+            refreshTokens: repository.NewRefreshTokenRepository(pool),
+            userTokens:    repository.NewUserTokenRepository(pool),
+            recoveryCodes: repository.NewRecoveryCodeRepository(pool),
+            revocations:   repository.NewRevocationStore(pool),
+            loginAttempts: repository.NewLoginAttemptStore(pool),
+            tx:            repository.NewTxManager(pool, pgx.TxOptions{IsoLevel: isoLevel}, cfg.Database.TxMaxRetries),
+            close:         pool.Close,
+        }
+    case "sqlite":
+        sqlDB, err := db.NewSQLiteDB(ctx, cfg)
+        if err != nil {
+            return nil, err
+        }
+
+        s = &storage{
+            users:         repository.NewSQLiteUserRepository(sqlDB),
+            refreshTokens: repository.NewSQLiteRefreshTokenRepository(sqlDB),
+            userTokens:    repository.NewSQLiteUserTokenRepository(sqlDB),
+            recoveryCodes: repository.NewSQLiteRecoveryCodeRepository(sqlDB),
+            revocations:   repository.NewSQLiteRevocationStore(sqlDB),
+            loginAttempts: repository.NewSQLiteLoginAttemptStore(sqlDB),
+            tx:            repository.NewSQLiteTxManager(sqlDB),
+            close:         func() { sqlDB.Close() },
+        }
+    case "memory":
+        // Nothing survives a restart; meant for local development and demos.
+        s = &storage{
+            users:         repository.NewMemoryUserRepository(),
+            refreshTokens: repository.NewMemoryRefreshTokenRepository(),
+            userTokens:    repository.NewMemoryUserTokenRepository(),
+            recoveryCodes: repository.NewMemoryRecoveryCodeRepository(),
+            revocations:   repository.NewMemoryRevocationStore(),
+            loginAttempts: repository.NewMemoryLoginAttemptStore(),
+            tx:            repository.NewMemoryTxManager(),
+            close:         func() {},
+        }
+    default:
+        return nil, fmt.Errorf("unknown database driver %q", cfg.Database.Driver)
+    }
+
+    if cfg.Auth.RevocationStore == "memory" {
+        s.revocations = repository.NewMemoryRevocationStore()
+    }
+    if cfg.Auth.Lockout.Store == "memory" {
+        s.loginAttempts = repository.NewMemoryLoginAttemptStore()
+    }
+
+    return s, nil
+}
+
+func txIsoLevel(name string) (pgx.TxIsoLevel, error) {
+    switch level := pgx.TxIsoLevel(name); level {
+    case pgx.ReadCommitted, pgx.RepeatableRead, pgx.Serializable:
+        return level, nil
+    default:
+        return "", fmt.Errorf("unknown transaction isolation level %q", name)
+    }
+}
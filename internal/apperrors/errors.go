@@ -0,0 +1,95 @@
+package apperrors
+
+import (
+    "errors"
+    "time"
+)
+
+// Error kinds. Every domain error wraps exactly one of them, so callers can
+// classify any error with errors.Is and handlers can map it to a status code.
+var (
+    ErrNotFound           = errors.New("not found")
+    ErrConflict           = errors.New("conflict")
+    ErrValidation         = errors.New("validation failed")
+    ErrForbidden          = errors.New("forbidden")
+    ErrUnauthorized       = errors.New("unauthorized")
+    ErrRateLimited        = errors.New("rate limited")
+    ErrLocked             = errors.New("locked")
+    ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+type Error struct {
+    kind    error
+    message string
+    err     error
+}
+
+func New(kind error, message string) *Error {
+    return &Error{kind: kind, message: message}
+}
+
+// Wrap classifies err as kind, keeping err in the chain for errors.Is/As.
+func Wrap(kind error, err error) *Error {
+    return &Error{kind: kind, message: err.Error(), err: err}
+}
+
+func NotFound(message string) *Error {
+    return New(ErrNotFound, message)
+}
+
+func Conflict(message string) *Error {
+    return New(ErrConflict, message)
+}
+
+func Validation(message string) *Error {
+    return New(ErrValidation, message)
+}
+
+func Forbidden(message string) *Error {
+    return New(ErrForbidden, message)
+}
+
+func Unauthorized(message string) *Error {
+    return New(ErrUnauthorized, message)
+}
+
+func RateLimited(message string) *Error {
+    return New(ErrRateLimited, message)
+}
+
+func Locked(message string) *Error {
+    return New(ErrLocked, message)
+}
+
+func PreconditionFailed(message string) *Error {
+    return New(ErrPreconditionFailed, message)
+}
+
+func (e *Error) Error() string {
+    return e.message
+}
+
+func (e *Error) Kind() error {
+    return e.kind
+}
+
+func (e *Error) Unwrap() []error {
+    if e.err == nil {
+        return []error{e.kind}
+    }
+    return []error{e.kind, e.err}
+}
+
+// RetryAfterError tells the client when a throttled operation may be retried.
+type RetryAfterError struct {
+    Err        error
+    RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+    return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+    return e.Err
+}
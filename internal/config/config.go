@@ -9,26 +9,108 @@ type Config struct {
     Server   ServerConfig   `mapstructure:"server"`
     Database DatabaseConfig `mapstructure:"database"`
     Auth     AuthConfig     `mapstructure:"auth"`
+    Mail     MailConfig     `mapstructure:"mail"`
+    Users    UsersConfig    `mapstructure:"users"`
+    I18n     I18nConfig     `mapstructure:"i18n"`
+    Log      LogConfig      `mapstructure:"log"`
 }
 
 type ServerConfig struct {
-    Port int `mapstructure:"port"`
+    Port           int  `mapstructure:"port"`
+    RequireIfMatch bool `mapstructure:"require_if_match"`
 }
 
 type DatabaseConfig struct {
+    Driver   string `mapstructure:"driver"`
     Host     string `mapstructure:"host"`
     Port     int    `mapstructure:"port"`
     User     string `mapstructure:"user"`
     Password string `mapstructure:"password"`
     Name     string `mapstructure:"name"`
     SSLMode  string `mapstructure:"sslmode"`
-}
 
+    Path string `mapstructure:"path"`
+
+    AutoMigrate bool `mapstructure:"auto_migrate"`
 
+    TxIsolation  string `mapstructure:"tx_isolation"`
+    TxMaxRetries int    `mapstructure:"tx_max_retries"`
+}
 
 type AuthConfig struct {
-    JWTSecret   string        `mapstructure:"jwt_secret"`
-    TokenExpiry time.Duration `mapstructure:"token_expiry"`
+    JWTSecret           string        `mapstructure:"jwt_secret"`
+    TokenExpiry         time.Duration `mapstructure:"token_expiry"`
+    RefreshTokenExpiry  time.Duration `mapstructure:"refresh_token_expiry"`
+    RevocationStore     string        `mapstructure:"revocation_store"`
+    Signing             SigningConfig `mapstructure:"signing"`
+    PasswordResetURL    string        `mapstructure:"password_reset_url"`
+    PasswordResetExpiry time.Duration `mapstructure:"password_reset_expiry"`
+
+    RequireEmailVerification   bool          `mapstructure:"require_email_verification"`
+    EmailVerificationURL       string        `mapstructure:"email_verification_url"`
+    EmailVerificationExpiry    time.Duration `mapstructure:"email_verification_expiry"`
+    VerificationResendInterval time.Duration `mapstructure:"verification_resend_interval"`
+
+    TOTPIssuer         string        `mapstructure:"totp_issuer"`
+    MFAChallengeExpiry time.Duration `mapstructure:"mfa_challenge_expiry"`
+    RequireAdminMFA    bool          `mapstructure:"require_admin_mfa"`
+
+    Lockout LockoutConfig `mapstructure:"lockout"`
+}
+
+type LockoutConfig struct {
+    Store           string        `mapstructure:"store"`
+    MaxAttempts     int           `mapstructure:"max_attempts"`
+    IPMaxAttempts   int           `mapstructure:"ip_max_attempts"`
+    BaseDelay       time.Duration `mapstructure:"base_delay"`
+    MaxDelay        time.Duration `mapstructure:"max_delay"`
+    LockoutDuration time.Duration `mapstructure:"lockout_duration"`
+    Window          time.Duration `mapstructure:"window"`
+}
+
+type SigningConfig struct {
+    Algorithm string             `mapstructure:"algorithm"`
+    ActiveKID string             `mapstructure:"active_kid"`
+    Keys      []SigningKeyConfig `mapstructure:"keys"`
+}
+
+// SigningKeyConfig describes one signing key. Algorithm defaults to
+// SigningConfig.Algorithm, so keys of different types can be rotated.
+type SigningKeyConfig struct {
+    KID            string `mapstructure:"kid"`
+    Algorithm      string `mapstructure:"algorithm"`
+    PrivateKeyFile string `mapstructure:"private_key_file"`
+    PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+type MailConfig struct {
+    Driver string     `mapstructure:"driver"`
+    From   string     `mapstructure:"from"`
+    Dir    string     `mapstructure:"dir"`
+    SMTP   SMTPConfig `mapstructure:"smtp"`
+}
+
+type SMTPConfig struct {
+    Host     string `mapstructure:"host"`
+    Port     int    `mapstructure:"port"`
+    Username string `mapstructure:"username"`
+    Password string `mapstructure:"password"`
+}
+
+type UsersConfig struct {
+    DeletedRetention time.Duration `mapstructure:"deleted_retention"`
+    PurgeInterval    time.Duration `mapstructure:"purge_interval"`
+}
+
+type I18nConfig struct {
+    DefaultLocale string `mapstructure:"default_locale"`
+    Dir           string `mapstructure:"dir"`
+}
+
+type LogConfig struct {
+    Level  string            `mapstructure:"level"`
+    Format string            `mapstructure:"format"`
+    Redact map[string]string `mapstructure:"redact"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -36,12 +118,65 @@ func LoadConfig() (*Config, error) {
     viper.SetConfigType("yaml")
     viper.AddConfigPath(".")
     
-    viper.SetDefault("auth.token_expiry", "24h")
+    viper.SetDefault("server.require_if_match", false)
+    viper.SetDefault("database.driver", "postgres")
+    viper.SetDefault("database.path", "data/go-crud-api.db")
+    viper.SetDefault("database.auto_migrate", false)
+    viper.SetDefault("database.tx_isolation", "read committed")
+    viper.SetDefault("database.tx_max_retries", 3)
+    viper.SetDefault("auth.token_expiry", "15m")
+    viper.SetDefault("auth.refresh_token_expiry", "720h")
+    viper.SetDefault("auth.revocation_store", "postgres")
+    viper.SetDefault("auth.signing.algorithm", "HS256")
+    viper.SetDefault("auth.password_reset_url", "http://localhost:8080/reset-password")
+    viper.SetDefault("auth.password_reset_expiry", "1h")
+    viper.SetDefault("auth.require_email_verification", false)
+    viper.SetDefault("auth.email_verification_url", "http://localhost:8080/api/users/verify")
+    viper.SetDefault("auth.email_verification_expiry", "24h")
+    viper.SetDefault("auth.verification_resend_interval", "1m")
+    viper.SetDefault("auth.totp_issuer", "go-crud-api")
+    viper.SetDefault("auth.mfa_challenge_expiry", "5m")
+    viper.SetDefault("auth.require_admin_mfa", true)
+    viper.SetDefault("auth.lockout.store", "postgres")
+    viper.SetDefault("auth.lockout.max_attempts", 5)
+    viper.SetDefault("auth.lockout.ip_max_attempts", 50)
+    viper.SetDefault("auth.lockout.base_delay", "1s")
+    viper.SetDefault("auth.lockout.max_delay", "1m")
+    viper.SetDefault("auth.lockout.lockout_duration", "15m")
+    viper.SetDefault("auth.lockout.window", "1h")
+    viper.SetDefault("mail.driver", "log")
+    viper.SetDefault("mail.from", "no-reply@localhost")
+    viper.SetDefault("mail.dir", "mail")
+    viper.SetDefault("mail.smtp.port", 587)
+    viper.SetDefault("users.deleted_retention", "720h")
+    viper.SetDefault("users.purge_interval", "1h")
+    viper.SetDefault("i18n.default_locale", "en")
+    viper.SetDefault("i18n.dir", "")
+    viper.SetDefault("log.level", "info")
+    viper.SetDefault("log.format", "json")
+    viper.SetDefault("log.redact.email", "mask")
+    viper.SetDefault("log.redact.to", "mask")
+    viper.SetDefault("log.redact.password", "remove")
+    viper.SetDefault("log.redact.token", "remove")
+    viper.SetDefault("log.redact.refresh_token", "remove")
+    viper.SetDefault("log.redact.mfa_token", "remove")
+    viper.SetDefault("log.redact.authorization", "remove")
+    viper.SetDefault("log.redact.code", "remove")
+    viper.SetDefault("log.redact.recovery_code", "remove")
+    viper.SetDefault("log.redact.totp_secret", "remove")
     
     if err := viper.ReadInConfig(); err != nil {
         return nil, err
     }
 
+    // The log mailer is there to show mail during local development, so its
+    // bodies stay in the log unless log.redact.body says otherwise.
+    if viper.GetString("mail.driver") == "log" {
+        viper.SetDefault("log.redact.body", "none")
+    } else {
+        viper.SetDefault("log.redact.body", "remove")
+    }
+
     var cfg Config
     if err := viper.Unmarshal(&cfg); err != nil {
         return nil, err
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+
+	"github.com/MorozkoArt/go-crud-api/internal/db/migrations"
+	"github.com/MorozkoArt/go-crud-api/internal/logging"
+)
+
+// Migrator applies the embedded migrations. On PostgreSQL every command
+// takes an advisory lock, so several instances starting at once do not
+// race each other.
+type Migrator struct {
+	provider *goose.Provider
+	// closeDB is false when the *sql.DB belongs to the caller.
+	closeDB bool
+}
+
+func NewMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+
+	return newMigrator(goose.DialectPostgres, stdlib.OpenDBFromPool(pool), migrations.FS, true,
+		goose.WithSessionLocker(locker))
+}
+
+// NewSQLiteMigrator applies the SQLite migrations to db. SQLite is meant
+// for a single local instance, so no lock is taken.
+func NewSQLiteMigrator(db *sql.DB) (*Migrator, error) {
+	return newMigrator(goose.DialectSQLite3, db, migrations.SQLiteFS, false)
+}
+
+func newMigrator(dialect goose.Dialect, db *sql.DB, fsys fs.FS, closeDB bool, opts ...goose.ProviderOption) (*Migrator, error) {
+	provider, err := goose.NewProvider(dialect, db, fsys, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{provider: provider, closeDB: closeDB}, nil
+}
+
+func (m *Migrator) Close() error {
+	if !m.closeDB {
+		return nil
+	}
+	return m.provider.Close()
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	for _, result := range results {
+		logging.Info(ctx, "migration applied", "file", result.Source.Path, "duration", result.Duration)
+	}
+	return err
+}
+
+func (m *Migrator) Down(ctx context.Context) error {
+	result, err := m.provider.Down(ctx)
+	if err != nil {
+		return err
+	}
+
+	logging.Info(ctx, "migration rolled back", "file", result.Source.Path, "duration", result.Duration)
+	return nil
+}
+
+// Redo rolls back the latest migration and applies it again.
+func (m *Migrator) Redo(ctx context.Context) error {
+	if err := m.Down(ctx); err != nil {
+		return err
+	}
+
+	result, err := m.provider.UpByOne(ctx)
+	if err != nil {
+		return err
+	}
+
+	logging.Info(ctx, "migration applied", "file", result.Source.Path, "duration", result.Duration)
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
+
+// CheckVersion returns an error unless the database schema is exactly at
+// the latest embedded migration.
+func (m *Migrator) CheckVersion(ctx context.Context) error {
+	current, target, err := m.provider.GetVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	if current < target {
+		return fmt.Errorf("database schema version %d is behind %d, run \"migrate up\"", current, target)
+	}
+	if current > target {
+		return fmt.Errorf("database schema version %d is newer than %d known to this binary", current, target)
+	}
+
+	return nil
+}
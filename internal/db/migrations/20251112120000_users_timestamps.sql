@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+UPDATE users SET updated_at = COALESCE(updated_at, created_at);
+ALTER TABLE users ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE users ALTER COLUMN updated_at SET NOT NULL;
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMPTZ;
+
+CREATE INDEX idx_users_updated_at_id ON users(updated_at, id);
+CREATE INDEX idx_users_last_login_at_id ON users((COALESCE(last_login_at, to_timestamp(0))), id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_last_login_at_id;
+DROP INDEX IF EXISTS idx_users_updated_at_id;
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
+ALTER TABLE users ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_token_revocations ADD COLUMN generation BIGINT NOT NULL DEFAULT 0;
+UPDATE user_token_revocations SET generation = 1;
+ALTER TABLE user_token_revocations DROP COLUMN revoked_before;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_token_revocations ADD COLUMN revoked_before TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE user_token_revocations ALTER COLUMN revoked_before DROP DEFAULT;
+ALTER TABLE user_token_revocations DROP COLUMN generation;
+-- +goose StatementEnd
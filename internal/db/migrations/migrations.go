@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the PostgreSQL migrations compiled into the binary.
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLiteFS holds the SQLite migrations. SQLite starts from a single
+// migration with the current schema instead of replaying PostgreSQL's history.
+var SQLiteFS, _ = fs.Sub(sqliteFS, "sqlite")
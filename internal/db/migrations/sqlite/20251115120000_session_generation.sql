@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_token_revocations ADD COLUMN generation INTEGER NOT NULL DEFAULT 0;
+UPDATE user_token_revocations SET generation = 1;
+ALTER TABLE user_token_revocations DROP COLUMN revoked_before;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_token_revocations ADD COLUMN revoked_before TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+UPDATE user_token_revocations SET revoked_before = datetime('now');
+ALTER TABLE user_token_revocations DROP COLUMN generation;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE login_attempts ADD COLUMN previous_failure_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE login_attempts DROP COLUMN previous_failure_at;
+-- +goose StatementEnd
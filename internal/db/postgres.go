@@ -3,21 +3,45 @@ package db
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/MorozkoArt/go-crud-api/internal/config"
+	"github.com/MorozkoArt/go-crud-api/internal/logging"
 )
 
+// NewPostgresDB connects to PostgreSQL, applies pending migrations when
+// database.auto_migrate is set and refuses to continue if the schema
+// version does not match the migrations embedded in the binary.
 func NewPostgresDB(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
+	pool, err := Connect(ctx, cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := NewMigrator(pool)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	defer migrator.Close()
+
+	if err := prepareSchema(ctx, migrator, cfg.Database.AutoMigrate); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+func Connect(ctx context.Context, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
 	dsn := fmt.Sprintf(
 		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Name,
-		cfg.Database.SSLMode,
+		cfg.User,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.Name,
+		cfg.SSLMode,
 	)
 
 	pool, err := pgxpool.New(ctx, dsn)
@@ -26,9 +50,20 @@ func NewPostgresDB(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, erro
 	}
 
 	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, err
 	}
 
-	log.Println("Connection to PostgreSQL established")
+	logging.Info(ctx, "connection to PostgreSQL established")
 	return pool, nil
 }
+
+func prepareSchema(ctx context.Context, migrator *Migrator, autoMigrate bool) error {
+	if autoMigrate {
+		if err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("auto-migrate: %w", err)
+		}
+	}
+
+	return migrator.CheckVersion(ctx)
+}
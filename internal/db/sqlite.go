@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/MorozkoArt/go-crud-api/internal/config"
+	"github.com/MorozkoArt/go-crud-api/internal/logging"
+)
+
+// NewSQLiteDB opens the SQLite database file and prepares its schema the
+// same way NewPostgresDB does.
+func NewSQLiteDB(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
+	db, err := OpenSQLite(ctx, cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := NewSQLiteMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	defer migrator.Close()
+
+	if err := prepareSchema(ctx, migrator, cfg.Database.AutoMigrate); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// OpenSQLite opens database.path, creating the file and its directory if
+// needed. Times are written in a fixed UTC layout so that they compare
+// correctly as text, and a single connection serialises all access,
+// which also keeps ":memory:" databases intact.
+func OpenSQLite(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, error) {
+	if cfg.Path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_time_format", "sqlite")
+
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?%s", cfg.Path, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	logging.Info(ctx, "SQLite database opened", "path", cfg.Path)
+	return db, nil
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+
+    "github.com/MorozkoArt/go-crud-api/internal/services"
+)
+
+type AuthHandler struct {
+    authService services.AuthService
+}
+
+func NewAuthHandler(authService services.AuthService) *AuthHandler {
+    return &AuthHandler{
+        authService: authService,
+    }
+}
+
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Cache-Control", "public, max-age=300")
+    w.WriteHeader(http.StatusOK)
+    json.NewEncoder(w).Encode(h.authService.JWKS())
+}
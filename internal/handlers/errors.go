@@ -0,0 +1,70 @@
+package handlers
+
+import (
+    "errors"
+    "math"
+    "net/http"
+    "strconv"
+    "time"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/go-playground/validator/v10"
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/i18n"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/problem"
+)
+
+var errorStatuses = map[error]int{
+    apperrors.ErrNotFound:           http.StatusNotFound,
+    apperrors.ErrConflict:           http.StatusConflict,
+    apperrors.ErrValidation:         http.StatusBadRequest,
+    apperrors.ErrForbidden:          http.StatusForbidden,
+    apperrors.ErrUnauthorized:       http.StatusUnauthorized,
+    apperrors.ErrRateLimited:        http.StatusTooManyRequests,
+    apperrors.ErrLocked:             http.StatusLocked,
+    apperrors.ErrPreconditionFailed: http.StatusPreconditionFailed,
+}
+
+// handleError is the single place where errors from the service layer are
+// turned into HTTP responses. The outermost apperrors.Error decides the
+// status; anything unclassified is logged and reported as a 500.
+func handleError(w http.ResponseWriter, r *http.Request, err error) {
+    var retryErr *apperrors.RetryAfterError
+    if errors.As(err, &retryErr) {
+        setRetryAfter(w, retryErr.RetryAfter)
+    }
+
+    var appErr *apperrors.Error
+    if errors.As(err, &appErr) {
+        if status, ok := errorStatuses[appErr.Kind()]; ok {
+            p := problem.New(status, errorMessage(err.Error()))
+
+            var validationErrs validator.ValidationErrors
+            if errors.As(err, &validationErrs) {
+                p.Errors = problem.FieldErrors(r.Context(), validationErrs)
+                p.Detail = i18n.T(r.Context(), "Validation failed") + ": " + problem.Detail(p.Errors)
+            }
+
+            problem.Write(w, r, p)
+            return
+        }
+    }
+
+    logging.Error(r.Context(), "internal error", "error", err)
+    sendError(w, r, "Internal server error", http.StatusInternalServerError)
+}
+
+func errorMessage(message string) string {
+    r, size := utf8.DecodeRuneInString(message)
+    return string(unicode.ToUpper(r)) + message[size:]
+}
+
+func setRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+    seconds := int64(math.Ceil(retryAfter.Seconds()))
+    if seconds < 1 {
+        seconds = 1
+    }
+    w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
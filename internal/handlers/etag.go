@@ -0,0 +1,76 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+)
+
+func userETag(version int64) string {
+    return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+func splitETags(header string) []string {
+    var tags []string
+    for _, tag := range strings.Split(header, ",") {
+        if tag = strings.TrimSpace(tag); tag != "" {
+            tags = append(tags, tag)
+        }
+    }
+    return tags
+}
+
+// parseIfMatch returns the user versions listed in the If-Match header.
+// A nil result means the header is absent or "*"; ok is false when the
+// header lists only tags that can never match, such as weak ones.
+func parseIfMatch(r *http.Request) (versions []int64, ok bool) {
+    header := r.Header.Get("If-Match")
+    if header == "" || strings.TrimSpace(header) == "*" {
+        return nil, true
+    }
+
+    for _, tag := range splitETags(header) {
+        if !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) || len(tag) < 2 {
+            continue
+        }
+
+        version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+        if err != nil {
+            continue
+        }
+        versions = append(versions, version)
+    }
+
+    return versions, len(versions) > 0
+}
+
+// matchIfNoneMatch reports whether the If-None-Match header matches etag
+// using weak comparison.
+func matchIfNoneMatch(r *http.Request, etag string) bool {
+    header := r.Header.Get("If-None-Match")
+    if header == "" {
+        return false
+    }
+
+    for _, tag := range splitETags(header) {
+        if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+            return true
+        }
+    }
+    return false
+}
+
+func (h *UserHandler) preconditions(w http.ResponseWriter, r *http.Request) ([]int64, bool) {
+    if h.requireIfMatch && r.Header.Get("If-Match") == "" {
+        sendError(w, r, "If-Match header required", http.StatusPreconditionRequired)
+        return nil, false
+    }
+
+    versions, ok := parseIfMatch(r)
+    if !ok {
+        sendError(w, r, "Precondition failed", http.StatusPreconditionFailed)
+        return nil, false
+    }
+
+    return versions, true
+}
@@ -2,104 +2,237 @@ package handlers
 
 import (
     "encoding/json"
+    "fmt"
+    "io"
+    "mime"
+    "net"
     "net/http"
     "strconv"
 
     "github.com/go-chi/chi/v5"
+    "github.com/MorozkoArt/go-crud-api/internal/middleware"
     "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/problem"
     "github.com/MorozkoArt/go-crud-api/internal/services"
     "github.com/MorozkoArt/go-crud-api/internal/utils"
 )
 
 type UserHandler struct {
-    userService services.UserService
+    userService    services.UserService
+    requireIfMatch bool
 }
 
-func NewUserHandler(userService services.UserService) *UserHandler {
+func NewUserHandler(userService services.UserService, requireIfMatch bool) *UserHandler {
     return &UserHandler{
-        userService: userService,
+        userService:    userService,
+        requireIfMatch: requireIfMatch,
     }
 }
 
 type Response struct {
     Success bool        `json:"success"`
     Data    interface{} `json:"data,omitempty"`
+    Meta    *Meta       `json:"meta,omitempty"`
     Error   string      `json:"error,omitempty"`
 }
 
+type Meta struct {
+    Total      int64  `json:"total"`
+    Limit      int    `json:"limit"`
+    Offset     int    `json:"offset"`
+    NextCursor string `json:"next_cursor,omitempty"`
+}
+
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
     var req models.RegisterRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        sendError(w, "Invalid request body", http.StatusBadRequest)
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
         return
     }
 
     if err := utils.ValidateStruct(req); err != nil {
-        sendError(w, err.Error(), http.StatusBadRequest)
+        handleError(w, r, err)
         return
     }
 
-    if err := h.userService.Register(r.Context(), &req); err != nil {
-        if err.Error() == "user already exists" {
-            sendError(w, "User with this email already exists", http.StatusConflict)
-        } else {
-            sendError(w, "Internal server error", http.StatusInternalServerError)
-        }
+    user, err := h.userService.Register(r.Context(), &req)
+    if err != nil {
+        handleError(w, r, err)
         return
     }
 
-    sendSuccess(w, "User registered successfully", http.StatusCreated)
+    w.Header().Set("Location", fmt.Sprintf("/api/users/%d", user.ID))
+    w.Header().Set("ETag", userETag(user.Version))
+    sendSuccess(w, user, http.StatusCreated)
 }
 
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
     var req models.LoginRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        sendError(w, "Invalid request body", http.StatusBadRequest)
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
         return
     }
 
     if err := utils.ValidateStruct(req); err != nil {
-        sendError(w, err.Error(), http.StatusBadRequest)
+        handleError(w, r, err)
         return
     }
 
-    user, token, err := h.userService.Login(r.Context(), &req)
+    req.ClientIP = clientIP(r)
+
+    result, err := h.userService.Login(r.Context(), &req)
     if err != nil {
-        sendError(w, "Invalid email or password", http.StatusUnauthorized)
+        handleError(w, r, err)
+        return
+    }
+
+    sendLoginResult(w, result)
+}
+
+func (h *UserHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+    var req models.MFALoginRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := utils.ValidateStruct(req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    req.ClientIP = clientIP(r)
+
+    result, err := h.userService.LoginMFA(r.Context(), &req)
+    if err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendLoginResult(w, result)
+}
+
+func sendLoginResult(w http.ResponseWriter, result *models.LoginResult) {
+    if result.MFAToken != "" {
+        sendSuccess(w, map[string]interface{}{
+            "mfa_required": true,
+            "mfa_token":    result.MFAToken,
+        }, http.StatusOK)
         return
     }
 
     response := map[string]interface{}{
-        "token": token,
-        "user":  user,
+        "token":         result.Tokens.AccessToken,
+        "refresh_token": result.Tokens.RefreshToken,
+        "expires_in":    result.Tokens.ExpiresIn,
+        "user":          result.User,
     }
 
     sendSuccess(w, response, http.StatusOK)
 }
 
-func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-    users, err := h.userService.GetAllUsers(r.Context())
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+    var req models.RefreshRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := utils.ValidateStruct(req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    tokens, err := h.userService.RefreshToken(r.Context(), &req)
+    if err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendSuccess(w, tokens, http.StatusOK)
+}
+
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.ClaimsFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    var req models.LogoutRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.userService.Logout(r.Context(), claims, &req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.ClaimsFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    if err := h.userService.LogoutAll(r.Context(), claims.UserID); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    params, err := parseUserListParams(r)
+    if err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    list, err := h.userService.ListUsers(r.Context(), actor, params)
     if err != nil {
-        sendError(w, "Internal server error", http.StatusInternalServerError)
+        handleError(w, r, err)
         return
     }
-    sendSuccess(w, users, http.StatusOK)
+
+    sendList(w, list)
 }
 
 func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
     id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
     if err != nil {
-        sendError(w, "Invalid user ID", http.StatusBadRequest)
+        sendError(w, r, "Invalid user ID", http.StatusBadRequest)
         return
     }
 
-    user, err := h.userService.GetUserByID(r.Context(), id)
+    user, err := h.userService.GetUserByID(r.Context(), actor, id)
     if err != nil {
-        if err.Error() == "user not found" {
-            sendError(w, "User not found", http.StatusNotFound)
-        } else {
-            sendError(w, "Internal server error", http.StatusInternalServerError)
-        }
+        handleError(w, r, err)
+        return
+    }
+
+    etag := userETag(user.Version)
+    w.Header().Set("ETag", etag)
+    if matchIfNoneMatch(r, etag) {
+        w.WriteHeader(http.StatusNotModified)
         return
     }
 
@@ -107,68 +240,314 @@ func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
     id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
     if err != nil {
-        sendError(w, "Invalid user ID", http.StatusBadRequest)
+        sendError(w, r, "Invalid user ID", http.StatusBadRequest)
         return
     }
 
     var req models.UpdateUserRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        sendError(w, "Invalid request body", http.StatusBadRequest)
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
         return
     }
 
     if err := utils.ValidateStruct(req); err != nil {
-        sendError(w, err.Error(), http.StatusBadRequest)
+        handleError(w, r, err)
         return
     }
 
-    if err := h.userService.UpdateUser(r.Context(), id, &req); err != nil {
-        if err.Error() == "user not found" {
-            sendError(w, "User not found", http.StatusNotFound)
-        } else {
-            sendError(w, "Internal server error", http.StatusInternalServerError)
-        }
+    if req.IfMatch, ok = h.preconditions(w, r); !ok {
+        return
+    }
+
+    user, err := h.userService.UpdateUser(r.Context(), actor, id, &req)
+    if err != nil {
+        handleError(w, r, err)
         return
     }
 
+    w.Header().Set("ETag", userETag(user.Version))
     sendSuccess(w, "User updated successfully", http.StatusOK)
 }
 
+func (h *UserHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+    if err != nil {
+        sendError(w, r, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+    if err != nil {
+        sendError(w, r, "Unsupported content type", http.StatusUnsupportedMediaType)
+        return
+    }
+    if contentType == "application/json" {
+        contentType = models.MergePatchContentType
+    }
+    if contentType != models.MergePatchContentType && contentType != models.JSONPatchContentType {
+        w.Header().Set("Accept-Patch", models.MergePatchContentType+", "+models.JSONPatchContentType)
+        sendError(w, r, "Unsupported patch content type", http.StatusUnsupportedMediaType)
+        return
+    }
+
+    body, err := io.ReadAll(r.Body)
+    if err != nil {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    ifMatch, ok := h.preconditions(w, r)
+    if !ok {
+        return
+    }
+
+    user, err := h.userService.PatchUser(r.Context(), actor, id, &models.PatchRequest{
+        ContentType: contentType,
+        Body:        body,
+        IfMatch:     ifMatch,
+    })
+    if err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    w.Header().Set("ETag", userETag(user.Version))
+    sendSuccess(w, user, http.StatusOK)
+}
+
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
     id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
     if err != nil {
-        sendError(w, "Invalid user ID", http.StatusBadRequest)
+        sendError(w, r, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    ifMatch, ok := h.preconditions(w, r)
+    if !ok {
         return
     }
 
-    if err := h.userService.DeleteUser(r.Context(), id); err != nil {
-        if err.Error() == "user not found" {
-            sendError(w, "User not found", http.StatusNotFound)
-        } else {
-            sendError(w, "Internal server error", http.StatusInternalServerError)
-        }
+    if err := h.userService.DeleteUser(r.Context(), actor, id, ifMatch); err != nil {
+        handleError(w, r, err)
         return
     }
 
     w.WriteHeader(http.StatusNoContent)
 }
 
-func sendError(w http.ResponseWriter, message string, statusCode int) {
+func (h *UserHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+    if err != nil {
+        sendError(w, r, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.userService.RestoreUser(r.Context(), actor, id); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendSuccess(w, "User restored successfully", http.StatusOK)
+}
+
+func (h *UserHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+    if err != nil {
+        sendError(w, r, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    var req models.UpdateRoleRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := utils.ValidateStruct(req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    if err := h.userService.UpdateRole(r.Context(), actor, id, &req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendSuccess(w, "User role updated successfully", http.StatusOK)
+}
+
+func (h *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+    var req models.ForgotPasswordRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := utils.ValidateStruct(req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    if err := h.userService.ForgotPassword(r.Context(), &req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendSuccess(w, "If the account exists, a password reset link has been sent", http.StatusAccepted)
+}
+
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+    var req models.ResetPasswordRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := utils.ValidateStruct(req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    if err := h.userService.ResetPassword(r.Context(), &req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendSuccess(w, "Password has been reset successfully", http.StatusOK)
+}
+
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+    if err != nil {
+        sendError(w, r, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    var req models.ChangePasswordRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := utils.ValidateStruct(req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    tokens, err := h.userService.ChangePassword(r.Context(), actor, id, &req)
+    if err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendSuccess(w, tokens, http.StatusOK)
+}
+
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+    token := r.URL.Query().Get("token")
+    if token == "" {
+        sendError(w, r, "Verification token is required", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.userService.VerifyEmail(r.Context(), token); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendSuccess(w, "Email verified successfully", http.StatusOK)
+}
+
+func (h *UserHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+    var req models.ResendVerificationRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := utils.ValidateStruct(req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    if err := h.userService.ResendVerification(r.Context(), &req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendSuccess(w, "If the account exists and is not verified, a verification link has been sent", http.StatusAccepted)
+}
+
+func clientIP(r *http.Request) string {
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        return r.RemoteAddr
+    }
+    return host
+}
+
+func sendError(w http.ResponseWriter, r *http.Request, message string, statusCode int) {
+    problem.Write(w, r, problem.New(statusCode, message))
+}
+
+func sendSuccess(w http.ResponseWriter, data interface{}, statusCode int) {
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(statusCode)
     json.NewEncoder(w).Encode(Response{
-        Success: false,
-        Error:   message,
+        Success: true,
+        Data:    data,
     })
 }
 
-func sendSuccess(w http.ResponseWriter, data interface{}, statusCode int) {
+func sendList(w http.ResponseWriter, list *models.UserList) {
     w.Header().Set("Content-Type", "application/json")
-    w.WriteHeader(statusCode)
+    w.WriteHeader(http.StatusOK)
     json.NewEncoder(w).Encode(Response{
         Success: true,
-        Data:    data,
+        Data:    list.Users,
+        Meta: &Meta{
+            Total:      list.Total,
+            Limit:      list.Limit,
+            Offset:     list.Offset,
+            NextCursor: list.NextCursor,
+        },
     })
-}
\ No newline at end of file
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+func parseUserListParams(r *http.Request) (*models.UserListParams, error) {
+    q := r.URL.Query()
+    params := &models.UserListParams{
+        Cursor:        q.Get("cursor"),
+        EmailContains: q.Get("email_contains"),
+        NameContains:  q.Get("name_contains"),
+        Role:          q.Get("role"),
+    }
+
+    if v := q.Get("limit"); v != "" {
+        limit, err := strconv.Atoi(v)
+        if err != nil || limit < 1 {
+            return nil, apperrors.Validation("invalid limit")
+        }
+        params.Limit = limit
+    }
+
+    if v := q.Get("offset"); v != "" {
+        offset, err := strconv.Atoi(v)
+        if err != nil || offset < 0 {
+            return nil, apperrors.Validation("invalid offset")
+        }
+        params.Offset = offset
+    }
+
+    if params.Cursor != "" && params.Offset > 0 {
+        return nil, apperrors.Validation("cursor and offset cannot be combined")
+    }
+
+    if sort := q.Get("sort"); sort != "" {
+        if strings.HasPrefix(sort, "-") {
+            params.Desc = true
+            sort = sort[1:]
+        }
+        if !isUserSortField(sort) {
+            return nil, apperrors.Validation("invalid sort field")
+        }
+        params.Sort = sort
+    }
+
+    if params.Role != "" && params.Role != models.RoleUser && params.Role != models.RoleAdmin {
+        return nil, apperrors.Validation("invalid role")
+    }
+
+    if v := q.Get("include_deleted"); v != "" {
+        includeDeleted, err := strconv.ParseBool(v)
+        if err != nil {
+            return nil, apperrors.Validation("invalid include_deleted")
+        }
+        params.IncludeDeleted = includeDeleted
+    }
+
+    var err error
+    if params.CreatedAfter, err = parseTimeParam(q.Get("created_after")); err != nil {
+        return nil, apperrors.Validation("invalid created_after")
+    }
+    if params.CreatedBefore, err = parseTimeParam(q.Get("created_before")); err != nil {
+        return nil, apperrors.Validation("invalid created_before")
+    }
+    if params.UpdatedAfter, err = parseTimeParam(q.Get("updated_after")); err != nil {
+        return nil, apperrors.Validation("invalid updated_after")
+    }
+    if params.UpdatedBefore, err = parseTimeParam(q.Get("updated_before")); err != nil {
+        return nil, apperrors.Validation("invalid updated_before")
+    }
+    if params.LastLoginAfter, err = parseTimeParam(q.Get("last_login_after")); err != nil {
+        return nil, apperrors.Validation("invalid last_login_after")
+    }
+    if params.LastLoginBefore, err = parseTimeParam(q.Get("last_login_before")); err != nil {
+        return nil, apperrors.Validation("invalid last_login_before")
+    }
+
+    return params, nil
+}
+
+func isUserSortField(field string) bool {
+    for _, f := range models.UserSortFields {
+        if f == field {
+            return true
+        }
+    }
+    return false
+}
+
+func parseTimeParam(value string) (*time.Time, error) {
+    if value == "" {
+        return nil, nil
+    }
+
+    if t, err := time.Parse(time.RFC3339, value); err == nil {
+        return &t, nil
+    }
+
+    t, err := time.Parse("2006-01-02", value)
+    if err != nil {
+        return nil, err
+    }
+    return &t, nil
+}
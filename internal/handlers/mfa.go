@@ -0,0 +1,101 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/go-chi/chi/v5"
+    "github.com/MorozkoArt/go-crud-api/internal/middleware"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+func (h *UserHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+    if err != nil {
+        sendError(w, r, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    enrollment, err := h.userService.EnrollTOTP(r.Context(), actor, id)
+    if err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendSuccess(w, enrollment, http.StatusOK)
+}
+
+func (h *UserHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+    if err != nil {
+        sendError(w, r, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    var req models.ConfirmTOTPRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := utils.ValidateStruct(req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    codes, err := h.userService.ConfirmTOTP(r.Context(), actor, id, &req)
+    if err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    sendSuccess(w, map[string]interface{}{
+        "recovery_codes": codes,
+    }, http.StatusOK)
+}
+
+func (h *UserHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+    actor, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        sendError(w, r, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
+    id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+    if err != nil {
+        sendError(w, r, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    var req models.DisableTOTPRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendError(w, r, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := utils.ValidateStruct(req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    if err := h.userService.DisableTOTP(r.Context(), actor, id, &req); err != nil {
+        handleError(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,109 @@
+package i18n
+
+import (
+    "embed"
+    "encoding/json"
+    "fmt"
+    "io/fs"
+    "os"
+    "path"
+    "regexp"
+    "strings"
+)
+
+// stringSuffix selects a tag's message for string fields, e.g. "min_string"
+// reads "at least N characters" where "min" reads "at least N".
+const stringSuffix = "_string"
+
+//go:embed locales/*.json
+var embedded embed.FS
+
+var placeholderPattern = regexp.MustCompile(`\{(\d+)\}`)
+
+// catalog is one <locale>.json file. Messages are keyed by the English
+// text the API produces; validation messages are keyed by validator tag
+// and may use {0} for the field name and {1} for the rule parameter.
+type catalog struct {
+    Messages   map[string]string `json:"messages"`
+    Validation map[string]string `json:"validation"`
+}
+
+func (c *catalog) validationTags() []string {
+    tags := make([]string, 0, len(c.Validation))
+    for key := range c.Validation {
+        tags = append(tags, strings.TrimSuffix(key, stringSuffix))
+    }
+    return tags
+}
+
+func (c *catalog) merge(other *catalog) {
+    for key, text := range other.Messages {
+        c.Messages[key] = text
+    }
+    for key, text := range other.Validation {
+        c.Validation[key] = text
+    }
+}
+
+func (c *catalog) check() error {
+    for key, text := range c.Messages {
+        if placeholderPattern.MatchString(text) {
+            return fmt.Errorf("message %q: placeholders are not supported", key)
+        }
+    }
+    for key, text := range c.Validation {
+        for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+            if m[1] != "0" && m[1] != "1" && m[1] != "2" {
+                return fmt.Errorf("validation %q: unknown placeholder %s", key, m[0])
+            }
+        }
+    }
+    return nil
+}
+
+// loadCatalogs reads the embedded catalogs and then any *.json files in
+// dir, which may add new locales or override embedded messages.
+func loadCatalogs(dir string) (map[string]*catalog, error) {
+    catalogs := map[string]*catalog{}
+
+    if err := readCatalogs(embedded, "locales", catalogs); err != nil {
+        return nil, err
+    }
+    if dir != "" {
+        if err := readCatalogs(os.DirFS(dir), ".", catalogs); err != nil {
+            return nil, err
+        }
+    }
+
+    return catalogs, nil
+}
+
+func readCatalogs(fsys fs.FS, dir string, catalogs map[string]*catalog) error {
+    files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+    if err != nil {
+        return err
+    }
+
+    for _, file := range files {
+        data, err := fs.ReadFile(fsys, file)
+        if err != nil {
+            return err
+        }
+
+        c := &catalog{Messages: map[string]string{}, Validation: map[string]string{}}
+        if err := json.Unmarshal(data, c); err != nil {
+            return fmt.Errorf("%s: %w", file, err)
+        }
+        if err := c.check(); err != nil {
+            return fmt.Errorf("%s: %w", file, err)
+        }
+
+        locale := normalize(strings.TrimSuffix(path.Base(file), ".json"))
+        if existing, ok := catalogs[locale]; ok {
+            existing.merge(c)
+        } else {
+            catalogs[locale] = c
+        }
+    }
+    return nil
+}
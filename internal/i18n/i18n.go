@@ -0,0 +1,179 @@
+package i18n
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/go-playground/locales"
+    "github.com/go-playground/locales/en"
+    "github.com/go-playground/locales/ru"
+    ut "github.com/go-playground/universal-translator"
+    "github.com/go-playground/validator/v10"
+    en_translations "github.com/go-playground/validator/v10/translations/en"
+    ru_translations "github.com/go-playground/validator/v10/translations/ru"
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+)
+
+// builtinLocales provide plural rules and validator default translations
+// for the locales this repo ships with. Catalogs for any other language
+// reuse the English rules under their own locale name.
+var builtinLocales = map[string]struct {
+    locale   func() locales.Translator
+    validate func(*validator.Validate, ut.Translator) error
+}{
+    "en": {en.New, en_translations.RegisterDefaultTranslations},
+    "ru": {ru.New, ru_translations.RegisterDefaultTranslations},
+}
+
+// Translator selects a message catalog for a request and translates API
+// messages and validation errors with it.
+type Translator struct {
+    universal *ut.UniversalTranslator
+    fallback  ut.Translator
+}
+
+func New(cfg config.I18nConfig, v *validator.Validate) (*Translator, error) {
+    catalogs, err := loadCatalogs(cfg.Dir)
+    if err != nil {
+        return nil, err
+    }
+
+    defaultLocale := normalize(cfg.DefaultLocale)
+    if _, ok := catalogs[defaultLocale]; !ok {
+        return nil, fmt.Errorf("no message catalog for default locale %q", cfg.DefaultLocale)
+    }
+
+    names := make([]string, 0, len(catalogs))
+    for name := range catalogs {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    supported := make([]locales.Translator, 0, len(names))
+    for _, name := range names {
+        supported = append(supported, newLocale(name))
+    }
+    universal := ut.New(newLocale(defaultLocale), supported...)
+
+    t := &Translator{universal: universal, fallback: universal.GetFallback()}
+
+    tags := catalogs[defaultLocale].validationTags()
+    for _, name := range names {
+        trans, _ := universal.GetTranslator(name)
+        if builtin, ok := builtinLocales[name]; ok {
+            if err := builtin.validate(v, trans); err != nil {
+                return nil, fmt.Errorf("registering %s validator translations: %w", name, err)
+            }
+        }
+        if err := t.register(v, trans, catalogs[name], tags); err != nil {
+            return nil, fmt.Errorf("registering %s catalog: %w", name, err)
+        }
+    }
+
+    return t, nil
+}
+
+// Negotiate picks the translator for an Accept-Language header value,
+// falling back to the default locale.
+func (t *Translator) Negotiate(acceptLanguage string) ut.Translator {
+    trans, _ := t.universal.FindTranslator(parseAcceptLanguage(acceptLanguage)...)
+    return trans
+}
+
+func (t *Translator) register(v *validator.Validate, trans ut.Translator, c *catalog, defaultTags []string) error {
+    for key, text := range c.Messages {
+        if err := trans.Add(key, text, true); err != nil {
+            return err
+        }
+    }
+
+    tags := map[string]bool{}
+    for _, tag := range append(c.validationTags(), defaultTags...) {
+        tags[tag] = true
+    }
+
+    for tag := range tags {
+        err := v.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+            for _, key := range []string{tag, tag + stringSuffix} {
+                if text, ok := c.Validation[key]; ok {
+                    if err := trans.Add(key, text, true); err != nil {
+                        return err
+                    }
+                }
+            }
+            return nil
+        }, t.translateField)
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// namedLocale lets a catalog for a language without built-in locale data
+// reuse English rules under its own name.
+type namedLocale struct {
+    locales.Translator
+    name string
+}
+
+func (l namedLocale) Locale() string {
+    return l.name
+}
+
+func newLocale(name string) locales.Translator {
+    if builtin, ok := builtinLocales[name]; ok {
+        return builtin.locale()
+    }
+    return namedLocale{Translator: en.New(), name: name}
+}
+
+func normalize(locale string) string {
+    return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "-", "_"))
+}
+
+// parseAcceptLanguage returns the locales from an Accept-Language header
+// ordered by preference. Each regional tag is followed by its base
+// language, so "ru-RU" also matches the "ru" catalog.
+func parseAcceptLanguage(header string) []string {
+    type weighted struct {
+        locale string
+        q      float64
+    }
+
+    var tags []weighted
+    for _, part := range strings.Split(header, ",") {
+        fields := strings.Split(part, ";")
+        locale := normalize(fields[0])
+        if locale == "" || locale == "*" {
+            continue
+        }
+
+        q := 1.0
+        for _, param := range fields[1:] {
+            name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+            if ok && name == "q" {
+                if v, err := strconv.ParseFloat(value, 64); err == nil {
+                    q = v
+                }
+            }
+        }
+        if q <= 0 {
+            continue
+        }
+        tags = append(tags, weighted{locale, q})
+    }
+
+    sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+    result := make([]string, 0, len(tags)*2)
+    for _, tag := range tags {
+        result = append(result, tag.locale)
+        if base, _, ok := strings.Cut(tag.locale, "_"); ok {
+            result = append(result, base)
+        }
+    }
+    return result
+}
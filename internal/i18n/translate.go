@@ -0,0 +1,77 @@
+package i18n
+
+import (
+    "context"
+    "reflect"
+    "strconv"
+    "strings"
+
+    ut "github.com/go-playground/universal-translator"
+    "github.com/go-playground/validator/v10"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, trans ut.Translator) context.Context {
+    return context.WithValue(ctx, contextKey{}, trans)
+}
+
+func FromContext(ctx context.Context) ut.Translator {
+    trans, _ := ctx.Value(contextKey{}).(ut.Translator)
+    return trans
+}
+
+// T translates an English API message into the request's locale. Messages
+// without a translation are returned unchanged.
+func T(ctx context.Context, message string) string {
+    trans := FromContext(ctx)
+    if trans == nil {
+        return message
+    }
+
+    if translated, err := trans.T(message); err == nil {
+        return translated
+    }
+    return message
+}
+
+// FieldMessage translates a single validation error into the request's
+// locale.
+func FieldMessage(ctx context.Context, fe validator.FieldError) string {
+    trans := FromContext(ctx)
+    if trans == nil {
+        return fe.Error()
+    }
+    return fe.Translate(trans)
+}
+
+// translateField looks the tag up in the request's catalog first and in
+// the default locale's catalog second.
+func (t *Translator) translateField(trans ut.Translator, fe validator.FieldError) string {
+    params := fieldParams(fe)
+
+    for _, candidate := range []ut.Translator{trans, t.fallback} {
+        if fe.Kind() == reflect.String {
+            if msg, err := candidate.T(fe.Tag()+stringSuffix, params...); err == nil {
+                return msg
+            }
+        }
+        if msg, err := candidate.T(fe.Tag(), params...); err == nil {
+            return msg
+        }
+    }
+    return fe.Error()
+}
+
+// fieldParams returns the {0}, {1} and {2} placeholder values: the field
+// name, the rule parameter and, for password, the length bounds.
+func fieldParams(fe validator.FieldError) []string {
+    switch fe.Tag() {
+    case "password":
+        return []string{fe.Field(), strconv.Itoa(utils.PasswordMinLength), strconv.Itoa(utils.PasswordMaxLength)}
+    case "oneof":
+        return []string{fe.Field(), strings.ReplaceAll(fe.Param(), " ", ", "), ""}
+    }
+    return []string{fe.Field(), utils.ValidationParam(fe), ""}
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "log/slog"
+
+    "github.com/go-chi/chi/v5"
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+)
+
+type contextKey struct{}
+
+// New builds a logger writing to w in the configured format. Records logged
+// while a request is being served carry the chi route pattern, and all
+// output passes through the redaction rules of cfg.Redact.
+func New(cfg config.LogConfig, w io.Writer) (*slog.Logger, error) {
+    var level slog.Level
+    if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+        return nil, fmt.Errorf("invalid log level %q", cfg.Level)
+    }
+
+    redactor, err := newRedactor(cfg.Redact)
+    if err != nil {
+        return nil, err
+    }
+
+    opts := &slog.HandlerOptions{Level: level}
+
+    var handler slog.Handler
+    switch cfg.Format {
+    case "json":
+        handler = slog.NewJSONHandler(w, opts)
+    case "text":
+        handler = slog.NewTextHandler(w, opts)
+    default:
+        return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+    }
+
+    return slog.New(routeHandler{redactHandler{Handler: handler, redactor: redactor}}), nil
+}
+
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+    return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request logger, or the default logger outside of
+// a request.
+func FromContext(ctx context.Context) *slog.Logger {
+    if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+        return logger
+    }
+    return slog.Default()
+}
+
+// With returns a context whose logger adds args to every record.
+func With(ctx context.Context, args ...any) context.Context {
+    return NewContext(ctx, FromContext(ctx).With(args...))
+}
+
+func Debug(ctx context.Context, msg string, args ...any) {
+    FromContext(ctx).DebugContext(ctx, msg, args...)
+}
+
+func Info(ctx context.Context, msg string, args ...any) {
+    FromContext(ctx).InfoContext(ctx, msg, args...)
+}
+
+func Warn(ctx context.Context, msg string, args ...any) {
+    FromContext(ctx).WarnContext(ctx, msg, args...)
+}
+
+func Error(ctx context.Context, msg string, args ...any) {
+    FromContext(ctx).ErrorContext(ctx, msg, args...)
+}
+
+// routeHandler adds the route pattern of the request being served. chi only
+// knows the full pattern once routing has finished, so it is read from the
+// record's context rather than bound to the request logger up front.
+type routeHandler struct {
+    slog.Handler
+}
+
+func (h routeHandler) Handle(ctx context.Context, r slog.Record) error {
+    if rctx := chi.RouteContext(ctx); rctx != nil {
+        if pattern := rctx.RoutePattern(); pattern != "" {
+            r.AddAttrs(slog.String("route", pattern))
+        }
+    }
+    return h.Handler.Handle(ctx, r)
+}
+
+func (h routeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    return routeHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h routeHandler) WithGroup(name string) slog.Handler {
+    return routeHandler{h.Handler.WithGroup(name)}
+}
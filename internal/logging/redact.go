@@ -0,0 +1,220 @@
+package logging
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "log/slog"
+    "regexp"
+    "strings"
+    "unicode/utf8"
+)
+
+// Redaction modes for the log.redact settings.
+const (
+    RedactNone   = "none"
+    RedactMask   = "mask"
+    RedactHash   = "hash"
+    RedactRemove = "remove"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+    emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+    bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[^\s"',;\]}]+`)
+    jwtPattern    = regexp.MustCompile(`\beyJ[\w-]*\.[\w-]+\.[\w-]+`)
+)
+
+// redactHandler rewrites records before they reach the wrapped handler.
+// Attributes are redacted by key as configured, also inside groups and
+// structured values; every other string, including the message, has emails
+// masked and bearer tokens and JWTs removed, so PII that slips into an error
+// message does not reach the logs either.
+//
+// Redaction happens here rather than in HandlerOptions.ReplaceAttr, which
+// cannot drop the only member of a group without corrupting JSON output.
+type redactHandler struct {
+    slog.Handler
+    redactor *redactor
+}
+
+func (h redactHandler) Handle(ctx context.Context, r slog.Record) error {
+    out := slog.NewRecord(r.Time, r.Level, scrub(r.Message), r.PC)
+    r.Attrs(func(a slog.Attr) bool {
+        if a, ok := h.redactor.redact(a); ok {
+            out.AddAttrs(a)
+        }
+        return true
+    })
+    return h.Handler.Handle(ctx, out)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    return redactHandler{Handler: h.Handler.WithAttrs(h.redactor.redactAll(attrs)), redactor: h.redactor}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+    return redactHandler{Handler: h.Handler.WithGroup(name), redactor: h.redactor}
+}
+
+type redactor struct {
+    modes map[string]string
+}
+
+func newRedactor(modes map[string]string) (*redactor, error) {
+    r := &redactor{modes: make(map[string]string, len(modes))}
+    for key, mode := range modes {
+        switch mode {
+        case RedactNone, RedactMask, RedactHash, RedactRemove:
+        default:
+            return nil, fmt.Errorf("unknown redaction mode %q for %q", mode, key)
+        }
+        r.modes[strings.ToLower(key)] = mode
+    }
+    return r, nil
+}
+
+func (r *redactor) redactAll(attrs []slog.Attr) []slog.Attr {
+    out := make([]slog.Attr, 0, len(attrs))
+    for _, a := range attrs {
+        if a, ok := r.redact(a); ok {
+            out = append(out, a)
+        }
+    }
+    return out
+}
+
+// redact returns the attribute to log in place of a, or false when it is
+// removed. Groups left without members are removed too.
+func (r *redactor) redact(a slog.Attr) (slog.Attr, bool) {
+    a.Value = a.Value.Resolve()
+
+    if a.Value.Kind() == slog.KindGroup {
+        attrs := r.redactAll(a.Value.Group())
+        if len(attrs) == 0 {
+            return slog.Attr{}, false
+        }
+        return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}, true
+    }
+
+    mode, ok := r.modes[strings.ToLower(a.Key)]
+    if !ok {
+        return slog.Attr{Key: a.Key, Value: r.scrubValue(a.Value)}, true
+    }
+
+    switch mode {
+    case RedactRemove:
+        return slog.Attr{}, false
+    case RedactMask:
+        return slog.String(a.Key, mask(valueString(a.Value))), true
+    case RedactHash:
+        return slog.String(a.Key, hash(valueString(a.Value))), true
+    }
+    return a, true
+}
+
+func (r *redactor) scrubValue(v slog.Value) slog.Value {
+    switch v.Kind() {
+    case slog.KindString:
+        return slog.StringValue(scrub(v.String()))
+    case slog.KindAny:
+        if err, ok := v.Any().(error); ok {
+            return slog.StringValue(scrub(err.Error()))
+        }
+        return r.scrubAny(v.Any())
+    }
+    return v
+}
+
+// scrubAny redacts maps, structs and slices by way of their JSON form, so
+// the configured keys apply to their fields as well. Values that cannot be
+// encoded are logged as scrubbed text.
+func (r *redactor) scrubAny(v any) slog.Value {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return slog.StringValue(scrub(fmt.Sprint(v)))
+    }
+
+    dec := json.NewDecoder(bytes.NewReader(data))
+    dec.UseNumber()
+
+    var decoded any
+    if err := dec.Decode(&decoded); err != nil {
+        return slog.StringValue(scrub(fmt.Sprint(v)))
+    }
+    return slog.AnyValue(r.scrubJSON(decoded))
+}
+
+func (r *redactor) scrubJSON(v any) any {
+    switch v := v.(type) {
+    case map[string]any:
+        for key, value := range v {
+            mode, ok := r.modes[strings.ToLower(key)]
+            switch {
+            case !ok:
+                v[key] = r.scrubJSON(value)
+            case mode == RedactRemove:
+                delete(v, key)
+            case mode == RedactMask:
+                v[key] = mask(fmt.Sprint(value))
+            case mode == RedactHash:
+                v[key] = hash(fmt.Sprint(value))
+            }
+        }
+        return v
+    case []any:
+        for i, value := range v {
+            v[i] = r.scrubJSON(value)
+        }
+        return v
+    case string:
+        return scrub(v)
+    }
+    return v
+}
+
+// valueString is the text a masked or hashed attribute is computed from.
+func valueString(v slog.Value) string {
+    if v.Kind() == slog.KindAny {
+        if err, ok := v.Any().(error); ok {
+            return err.Error()
+        }
+        if data, err := json.Marshal(v.Any()); err == nil {
+            return string(data)
+        }
+    }
+    return v.String()
+}
+
+func scrub(s string) string {
+    s = bearerPattern.ReplaceAllString(s, "Bearer "+redacted)
+    s = jwtPattern.ReplaceAllString(s, redacted)
+    return emailPattern.ReplaceAllStringFunc(s, maskEmail)
+}
+
+// mask keeps the first character of an email's local part and its domain,
+// which is usually enough to tell accounts apart while debugging. Anything
+// but a bare email is replaced entirely.
+func mask(s string) string {
+    if emailPattern.FindString(s) == s && s != "" {
+        return maskEmail(s)
+    }
+    return redacted
+}
+
+func maskEmail(email string) string {
+    local, domain, _ := strings.Cut(email, "@")
+    first, _ := utf8.DecodeRuneInString(local)
+    return string(first) + "***@" + domain
+}
+
+// hash replaces the value with a stable pseudonym, so entries about the same
+// email can still be correlated. Emails are compared case-insensitively.
+func hash(s string) string {
+    sum := sha256.Sum256([]byte(strings.ToLower(s)))
+    return "sha256:" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+type fileMailer struct {
+    dir  string
+    from string
+}
+
+func NewFileMailer(dir, from string) Mailer {
+    return &fileMailer{dir: dir, from: from}
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg Message) error {
+    if err := os.MkdirAll(m.dir, 0o755); err != nil {
+        return err
+    }
+
+    name := fmt.Sprintf("%s-%s.eml",
+        time.Now().Format("20060102T150405.000000000"),
+        strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To))
+
+    return os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, msg), 0o644)
+}
+
+func buildMessage(from string, msg Message) []byte {
+    var b strings.Builder
+    fmt.Fprintf(&b, "From: %s\r\n", from)
+    fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+    fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+    fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+    b.WriteString("MIME-Version: 1.0\r\n")
+    b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+    b.WriteString("\r\n")
+    b.WriteString(msg.Body)
+    return []byte(b.String())
+}
@@ -0,0 +1,18 @@
+package mailer
+
+import (
+    "context"
+
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+)
+
+type logMailer struct{}
+
+func NewLogMailer() Mailer {
+    return &logMailer{}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg Message) error {
+    logging.Info(ctx, "mail sent", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+    return nil
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+)
+
+type Message struct {
+    To      string
+    Subject string
+    Body    string
+}
+
+type Mailer interface {
+    Send(ctx context.Context, msg Message) error
+}
+
+func New(cfg config.MailConfig) (Mailer, error) {
+    switch cfg.Driver {
+    case "", "log":
+        return NewLogMailer(), nil
+    case "file":
+        return NewFileMailer(cfg.Dir, cfg.From), nil
+    case "smtp":
+        return NewSMTPMailer(cfg.SMTP, cfg.From), nil
+    }
+    return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+}
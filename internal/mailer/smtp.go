@@ -0,0 +1,29 @@
+package mailer
+
+import (
+    "context"
+    "fmt"
+    "net/smtp"
+
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+)
+
+type smtpMailer struct {
+    cfg  config.SMTPConfig
+    from string
+}
+
+func NewSMTPMailer(cfg config.SMTPConfig, from string) Mailer {
+    return &smtpMailer{cfg: cfg, from: from}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+    addr := fmt.Sprintf("%s:%d", m.cfg.Host, m.cfg.Port)
+
+    var auth smtp.Auth
+    if m.cfg.Username != "" {
+        auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+    }
+
+    return smtp.SendMail(addr, auth, m.from, []string{msg.To}, buildMessage(m.from, msg))
+}
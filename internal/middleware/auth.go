@@ -5,13 +5,18 @@ import (
     "net/http"
     "strings"
 
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/problem"
     "github.com/MorozkoArt/go-crud-api/internal/services"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
 )
 
 type userKey string
 
 const (
     UserIDKey userKey = "user_id"
+    ClaimsKey userKey = "claims"
 )
 
 func AuthMiddleware(authService services.AuthService) func(http.Handler) http.Handler {
@@ -19,28 +24,49 @@ func AuthMiddleware(authService services.AuthService) func(http.Handler) http.Ha
         return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
             authHeader := r.Header.Get("Authorization")
             if authHeader == "" {
-                http.Error(w, `{"success": false, "error": "Authorization header required"}`, http.StatusUnauthorized)
+                problem.Write(w, r, problem.New(http.StatusUnauthorized, "Authorization header required"))
                 return
             }
             
             parts := strings.Split(authHeader, " ")
             if len(parts) != 2 || parts[0] != "Bearer" {
-                http.Error(w, `{"success": false, "error": "Invalid authorization format"}`, http.StatusUnauthorized)
+                problem.Write(w, r, problem.New(http.StatusUnauthorized, "Invalid authorization format"))
                 return
             }
             
             tokenString := parts[1]
             
-            claims, err := authService.ValidateToken(tokenString)
+            claims, err := authService.ValidateToken(r.Context(), tokenString)
             if err != nil {
-                http.Error(w, `{"success": false, "error": "Invalid or expired token"}`, http.StatusUnauthorized)
+                problem.Write(w, r, problem.New(http.StatusUnauthorized, "Invalid or expired token"))
                 return
             }
             
             ctx := r.Context()
             ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+            ctx = context.WithValue(ctx, ClaimsKey, claims)
+            ctx = logging.With(ctx, "actor_id", claims.UserID)
+            setAccessLogActor(ctx, claims.UserID)
             
             next.ServeHTTP(w, r.WithContext(ctx))
         })
     }
+}
+
+func ClaimsFromContext(ctx context.Context) (*utils.Claims, bool) {
+    claims, ok := ctx.Value(ClaimsKey).(*utils.Claims)
+    return claims, ok
+}
+
+func PrincipalFromContext(ctx context.Context) (models.Principal, bool) {
+    claims, ok := ClaimsFromContext(ctx)
+    if !ok {
+        return models.Principal{}, false
+    }
+
+    return models.Principal{
+        UserID: claims.UserID,
+        Role:   claims.Role,
+        MFA:    claims.MFA,
+    }, true
 }
\ No newline at end of file
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+    "net/http"
+
+    "github.com/MorozkoArt/go-crud-api/internal/problem"
+)
+
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            claims, ok := ClaimsFromContext(r.Context())
+            if !ok {
+                problem.Write(w, r, problem.New(http.StatusUnauthorized, "Authorization required"))
+                return
+            }
+
+            if !hasRole(claims.Role, roles) {
+                problem.Write(w, r, problem.New(http.StatusForbidden, "Forbidden"))
+                return
+            }
+
+            next.ServeHTTP(w, r)
+        })
+    }
+}
+
+func hasRole(role string, roles []string) bool {
+    for _, allowed := range roles {
+        if role == allowed {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+    "net/http"
+
+    "github.com/MorozkoArt/go-crud-api/internal/i18n"
+)
+
+// Locale picks the message catalog from Accept-Language and exposes it in
+// the request context.
+func Locale(translator *i18n.Translator) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            trans := translator.Negotiate(r.Header.Get("Accept-Language"))
+
+            w.Header().Set("Content-Language", trans.Locale())
+            w.Header().Add("Vary", "Accept-Language")
+            next.ServeHTTP(w, r.WithContext(i18n.NewContext(r.Context(), trans)))
+        })
+    }
+}
@@ -1,35 +1,83 @@
 package middleware
 
 import (
-    "log"
+    "context"
+    "log/slog"
     "net/http"
     "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/requestid"
 )
 
-func Logger(next http.Handler) http.Handler {
-    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        start := time.Now()
-        
-        wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
-        next.ServeHTTP(wrapped, r)
-        
-        log.Printf(
-            "[%s] %s %s %d %v",
-            r.Method,
-            r.URL.Path,
-            r.RemoteAddr,
-            wrapped.statusCode,
-            time.Since(start),
-        )
-    })
+type accessLogKey struct{}
+
+// accessLog holds fields that are only known further down the chain, such
+// as the authenticated user, so that the access log entry can include them.
+type accessLog struct {
+    actorID int64
+}
+
+// setAccessLogActor records the authenticated user for the access log.
+func setAccessLogActor(ctx context.Context, userID int64) {
+    if entry, ok := ctx.Value(accessLogKey{}).(*accessLog); ok {
+        entry.actorID = userID
+    }
+}
+
+// Logger puts a logger tagged with the request ID into the request context
+// and writes an access log entry once the response is sent.
+func Logger(logger *slog.Logger) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            start := time.Now()
+
+            entry := &accessLog{}
+            ctx := context.WithValue(r.Context(), accessLogKey{}, entry)
+            ctx = logging.NewContext(ctx, logger.With("request_id", requestid.FromContext(r.Context())))
+
+            wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+            next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+            level := slog.LevelInfo
+            switch {
+            case wrapped.statusCode >= http.StatusInternalServerError:
+                level = slog.LevelError
+            case wrapped.statusCode >= http.StatusBadRequest:
+                level = slog.LevelWarn
+            }
+
+            args := []any{
+                "method", r.Method,
+                "path", r.URL.Path,
+                "status", wrapped.statusCode,
+                "bytes", wrapped.bytes,
+                "duration", time.Since(start),
+                "remote_addr", r.RemoteAddr,
+                "user_agent", r.UserAgent(),
+            }
+            if entry.actorID != 0 {
+                args = append(args, "actor_id", entry.actorID)
+            }
+
+            logging.FromContext(ctx).Log(ctx, level, "request completed", args...)
+        })
+    }
 }
 
 type responseWriter struct {
     http.ResponseWriter
     statusCode int
+    bytes      int64
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
     rw.statusCode = code
     rw.ResponseWriter.WriteHeader(code)
-}
\ No newline at end of file
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+    n, err := rw.ResponseWriter.Write(b)
+    rw.bytes += int64(n)
+    return n, err
+}
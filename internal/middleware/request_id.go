@@ -0,0 +1,36 @@
+package middleware
+
+import (
+    "net/http"
+
+    "github.com/MorozkoArt/go-crud-api/internal/requestid"
+)
+
+const maxRequestIDLength = 128
+
+// RequestID keeps the client's X-Request-ID when it looks sane, otherwise
+// generates a new one, and exposes it in the context and response headers.
+func RequestID(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        id := r.Header.Get(requestid.Header)
+        if !validRequestID(id) {
+            id = requestid.New()
+        }
+
+        w.Header().Set(requestid.Header, id)
+        next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+    })
+}
+
+func validRequestID(id string) bool {
+    if id == "" || len(id) > maxRequestIDLength {
+        return false
+    }
+
+    for _, c := range id {
+        if c < '!' || c > '~' {
+            return false
+        }
+    }
+    return true
+}
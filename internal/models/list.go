@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+    DefaultListLimit = 20
+    MaxListLimit     = 100
+)
+
+var UserSortFields = []string{"id", "name", "email", "created_at", "updated_at", "last_login_at"}
+
+type UserListParams struct {
+    Limit           int
+    Offset          int
+    Cursor          string
+    Sort            string
+    Desc            bool
+    EmailContains   string
+    NameContains    string
+    Role            string
+    CreatedAfter    *time.Time
+    CreatedBefore   *time.Time
+    UpdatedAfter    *time.Time
+    UpdatedBefore   *time.Time
+    LastLoginAfter  *time.Time
+    // LastLoginBefore also matches users who have never logged in.
+    LastLoginBefore *time.Time
+    IncludeDeleted  bool
+}
+
+type UserPage struct {
+    Users      []User
+    Total      int64
+    NextCursor string
+}
+
+type UserList struct {
+    Users      []UserResponse
+    Total      int64
+    Limit      int
+    Offset     int
+    NextCursor string
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type LoginAttempt struct {
+    Key           string
+    Failures      int
+    LastFailureAt time.Time
+}
+
+// LoginAttemptLimit bounds the attempts counted for a key: once MaxFailures
+// attempts were made within Window, further attempts are refused until
+// Lockout has passed since the last one. MaxFailures <= 0 disables the limit.
+type LoginAttemptLimit struct {
+    MaxFailures int
+    Window      time.Duration
+    Lockout     time.Duration
+}
@@ -0,0 +1,22 @@
+package models
+
+const (
+    MergePatchContentType = "application/merge-patch+json"
+    JSONPatchContentType  = "application/json-patch+json"
+)
+
+type PatchRequest struct {
+    ContentType string
+    Body        []byte
+    IfMatch     []int64
+}
+
+// UserPatch holds the fields changed by a PATCH request; nil fields are left untouched.
+type UserPatch struct {
+    Name  *string `json:"name,omitempty" validate:"omitempty,min=2"`
+    Email *string `json:"email,omitempty" validate:"omitempty,email"`
+}
+
+func (p *UserPatch) IsEmpty() bool {
+    return p.Name == nil && p.Email == nil
+}
@@ -0,0 +1,11 @@
+package models
+
+type Principal struct {
+    UserID int64
+    Role   string
+    MFA    bool
+}
+
+func (p Principal) IsAdmin() bool {
+    return p.Role == RoleAdmin
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+type RefreshToken struct {
+    ID        int64
+    UserID    int64
+    FamilyID  string
+    TokenHash string
+    ExpiresAt time.Time
+    UsedAt    *time.Time
+    RevokedAt *time.Time
+    MFA       bool
+}
+
+const (
+    TokenPurposePasswordReset     = "password_reset"
+    TokenPurposeEmailVerification = "email_verification"
+)
+
+type UserToken struct {
+    ID        int64
+    UserID    int64
+    Purpose   string
+    TokenHash string
+    ExpiresAt time.Time
+    UsedAt    *time.Time
+    CreatedAt time.Time
+}
+
+type TokenPair struct {
+    AccessToken  string `json:"token"`
+    RefreshToken string `json:"refresh_token"`
+    ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshRequest struct {
+    RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+    RefreshToken string `json:"refresh_token"`
+}
@@ -1,30 +1,122 @@
 package models
 
+import "time"
+
+const (
+    RoleUser  = "user"
+    RoleAdmin = "admin"
+)
+
 type User struct {
 	ID 		 int64  `json:"id"`
 	Name 	 string `json:"name"`
 	Email 	 string `json:"email"`
 	Password string `json:"password,omitempty"`
+	Role 		 string `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	TOTPSecret 	 string `json:"-"`
+	TOTPEnabledAt *time.Time `json:"-"`
+	TOTPLastStep int64 `json:"-"`
+	CreatedAt 	 time.Time `json:"created_at"`
+	UpdatedAt 	 time.Time `json:"updated_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	DeletedAt 	 *time.Time `json:"deleted_at,omitempty"`
+	Version 	 int64 `json:"-"`
 }
 
 type UserResponse struct {
-    ID    int64  `json:"id"`
-    Name  string `json:"name"`
-    Email string `json:"email"`
+    ID            int64      `json:"id"`
+    Name          string     `json:"name"`
+    Email         string     `json:"email"`
+    Role          string     `json:"role"`
+    EmailVerified bool       `json:"email_verified"`
+    MFAEnabled    bool       `json:"mfa_enabled"`
+    CreatedAt     time.Time  `json:"created_at"`
+    UpdatedAt     time.Time  `json:"updated_at"`
+    LastLoginAt   *time.Time `json:"last_login_at"`
+    DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+    Version       int64      `json:"-"`
+}
+
+func (u *User) ToResponse() UserResponse {
+    return UserResponse{
+        ID:            u.ID,
+        Name:          u.Name,
+        Email:         u.Email,
+        Role:          u.Role,
+        EmailVerified: u.EmailVerifiedAt != nil,
+        MFAEnabled:    u.TOTPEnabledAt != nil,
+        CreatedAt:     u.CreatedAt,
+        UpdatedAt:     u.UpdatedAt,
+        LastLoginAt:   u.LastLoginAt,
+        DeletedAt:     u.DeletedAt,
+        Version:       u.Version,
+    }
 }
 
 type LoginRequest struct {
     Email    string `json:"email" validate:"required,email"`
     Password string `json:"password" validate:"required,min=6"`
+    ClientIP string `json:"-"`
 }
 
 type RegisterRequest struct {
     Name     string `json:"name" validate:"required,min=2"`
     Email    string `json:"email" validate:"required,email"`
-    Password string `json:"password" validate:"required,min=6"`
+    Password string `json:"password" validate:"required,password"`
 }
 
 type UpdateUserRequest struct {
-    Name  string `json:"name" validate:"required,min=2"`
+    Name    string  `json:"name" validate:"required,min=2"`
+    Email   string  `json:"email" validate:"required,email"`
+    IfMatch []int64 `json:"-"`
+}
+
+type UpdateRoleRequest struct {
+    Role string `json:"role" validate:"required,oneof=user admin"`
+}
+
+type ForgotPasswordRequest struct {
+    Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+    Token    string `json:"token" validate:"required"`
+    Password string `json:"password" validate:"required,password"`
+}
+
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"current_password" validate:"required"`
+    NewPassword     string `json:"new_password" validate:"required,password,nefield=CurrentPassword"`
+}
+
+type ResendVerificationRequest struct {
     Email string `json:"email" validate:"required,email"`
+}
+
+type LoginResult struct {
+    User     *UserResponse
+    Tokens   *TokenPair
+    MFAToken string
+}
+
+type MFALoginRequest struct {
+    MFAToken     string `json:"mfa_token" validate:"required"`
+    Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+    RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+    ClientIP     string `json:"-"`
+}
+
+type TOTPEnrollment struct {
+    Secret string `json:"secret"`
+    URI    string `json:"otpauth_uri"`
+}
+
+type ConfirmTOTPRequest struct {
+    Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type DisableTOTPRequest struct {
+    Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+    RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
 }
\ No newline at end of file
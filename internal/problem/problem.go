@@ -0,0 +1,101 @@
+package problem
+
+import (
+    "encoding/json"
+    "mime"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/MorozkoArt/go-crud-api/internal/i18n"
+    "github.com/MorozkoArt/go-crud-api/internal/requestid"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details object.
+type Problem struct {
+    Type      string       `json:"type"`
+    Title     string       `json:"title"`
+    Status    int          `json:"status"`
+    Detail    string       `json:"detail,omitempty"`
+    Instance  string       `json:"instance,omitempty"`
+    RequestID string       `json:"request_id,omitempty"`
+    Errors    []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+    Field   string `json:"field"`
+    Rule    string `json:"rule"`
+    Param   string `json:"param,omitempty"`
+    Message string `json:"message"`
+}
+
+func New(status int, detail string) *Problem {
+    return &Problem{
+        Type:   "about:blank",
+        Title:  http.StatusText(status),
+        Status: status,
+        Detail: detail,
+    }
+}
+
+// legacyResponse mirrors the success/error envelope that predates problem details.
+type legacyResponse struct {
+    Success bool   `json:"success"`
+    Error   string `json:"error"`
+}
+
+// Write sends p as application/problem+json, or as the legacy envelope when
+// the client asks for application/json without accepting problem details.
+// Title and detail are translated into the request's locale.
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+    p.Title = i18n.T(r.Context(), p.Title)
+    p.Detail = i18n.T(r.Context(), p.Detail)
+    if p.Instance == "" {
+        p.Instance = r.URL.Path
+    }
+    if p.RequestID == "" {
+        p.RequestID = requestid.FromContext(r.Context())
+    }
+
+    if PrefersLegacy(r) {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(p.Status)
+        json.NewEncoder(w).Encode(legacyResponse{Success: false, Error: p.Detail})
+        return
+    }
+
+    w.Header().Set("Content-Type", ContentType)
+    w.WriteHeader(p.Status)
+    json.NewEncoder(w).Encode(p)
+}
+
+// PrefersLegacy reports whether the Accept header lists application/json
+// but not application/problem+json. Clients that send no Accept header or
+// only wildcards get problem details.
+func PrefersLegacy(r *http.Request) bool {
+    var acceptsJSON bool
+    for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+        mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+        if err != nil || isZeroQ(params["q"]) {
+            continue
+        }
+
+        switch mediaType {
+        case ContentType:
+            return false
+        case "application/json":
+            acceptsJSON = true
+        }
+    }
+    return acceptsJSON
+}
+
+func isZeroQ(q string) bool {
+    if q == "" {
+        return false
+    }
+    v, err := strconv.ParseFloat(q, 64)
+    return err == nil && v == 0
+}
@@ -0,0 +1,43 @@
+package problem
+
+import (
+    "context"
+    "strings"
+
+    "github.com/go-playground/validator/v10"
+    "github.com/MorozkoArt/go-crud-api/internal/i18n"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+// FieldErrors converts validator errors into problem field errors. Field
+// names come from the validator's tag name function, i.e. JSON names;
+// messages are translated into the request's locale.
+func FieldErrors(ctx context.Context, errs validator.ValidationErrors) []FieldError {
+    fields := make([]FieldError, 0, len(errs))
+    for _, fe := range errs {
+        fields = append(fields, FieldError{
+            Field:   fieldPath(fe.Namespace()),
+            Rule:    fe.Tag(),
+            Param:   utils.ValidationParam(fe),
+            Message: i18n.FieldMessage(ctx, fe),
+        })
+    }
+    return fields
+}
+
+// Detail joins field messages into a single human readable sentence.
+func Detail(fields []FieldError) string {
+    messages := make([]string, 0, len(fields))
+    for _, f := range fields {
+        messages = append(messages, f.Message)
+    }
+    return strings.Join(messages, "; ")
+}
+
+// fieldPath drops the top-level struct name from a validator namespace.
+func fieldPath(namespace string) string {
+    if i := strings.Index(namespace, "."); i >= 0 {
+        return namespace[i+1:]
+    }
+    return namespace
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "strconv"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+var (
+    ErrInvalidCursor = apperrors.Validation("invalid cursor")
+    ErrInvalidSort   = apperrors.Validation("invalid sort field")
+)
+
+type sortColumn struct {
+    column string
+    cast   string
+}
+
+// Users who never logged in sort as if they last did at the Unix epoch,
+// which keeps keyset comparisons on last_login_at free of NULLs.
+var userSortColumns = map[string]sortColumn{
+    "id":            {column: "id", cast: "bigint"},
+    "name":          {column: "name", cast: "text"},
+    "email":         {column: "email", cast: "text"},
+    "created_at":    {column: "created_at", cast: "timestamptz"},
+    "updated_at":    {column: "updated_at", cast: "timestamptz"},
+    "last_login_at": {column: "COALESCE(last_login_at, to_timestamp(0))", cast: "timestamptz"},
+}
+
+type userCursor struct {
+    Sort  string `json:"s"`
+    Desc  bool   `json:"d,omitempty"`
+    Value string `json:"v"`
+    ID    int64  `json:"id"`
+}
+
+func encodeUserCursor(p *models.UserListParams, u *models.User) string {
+    data, _ := json.Marshal(userCursor{
+        Sort:  p.Sort,
+        Desc:  p.Desc,
+        Value: userSortValue(u, p.Sort),
+        ID:    u.ID,
+    })
+    return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserCursor(p *models.UserListParams) (*userCursor, error) {
+    data, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+    if err != nil {
+        return nil, ErrInvalidCursor
+    }
+
+    var c userCursor
+    if err := json.Unmarshal(data, &c); err != nil {
+        return nil, ErrInvalidCursor
+    }
+
+    if c.Sort != p.Sort || c.Desc != p.Desc {
+        return nil, ErrInvalidCursor
+    }
+
+    return &c, nil
+}
+
+func userSortValue(u *models.User, sort string) string {
+    switch sort {
+    case "name":
+        return u.Name
+    case "email":
+        return u.Email
+    case "created_at":
+        return u.CreatedAt.UTC().Format(time.RFC3339Nano)
+    case "updated_at":
+        return u.UpdatedAt.UTC().Format(time.RFC3339Nano)
+    case "last_login_at":
+        if u.LastLoginAt == nil {
+            return time.Unix(0, 0).UTC().Format(time.RFC3339Nano)
+        }
+        return u.LastLoginAt.UTC().Format(time.RFC3339Nano)
+    }
+    return strconv.FormatInt(u.ID, 10)
+}
+
+// dbNow is the current time at the microsecond precision of PostgreSQL
+// timestamps, used by the stores that keep time themselves.
+func dbNow() time.Time {
+    return time.Now().UTC().Truncate(time.Microsecond)
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+// LoginAttemptStore counts login attempts per key. Reserve counts an
+// attempt up front and only while the key is below its limit, so concurrent
+// requests cannot all pass a check before any of them is recorded; Release
+// takes back an attempt that turned out not to be a failure, including the
+// last failure time the reservation moved.
+type LoginAttemptStore interface {
+    Get(ctx context.Context, key string) (*models.LoginAttempt, error)
+    Reserve(ctx context.Context, key string, limit models.LoginAttemptLimit) (*models.LoginAttempt, bool, error)
+    Release(ctx context.Context, key string) error
+    Reset(ctx context.Context, key string) error
+}
+
+type loginAttemptStore struct {
+    db *pgxpool.Pool
+}
+
+func NewLoginAttemptStore(db *pgxpool.Pool) LoginAttemptStore {
+    return &loginAttemptStore{db: db}
+}
+
+func (s *loginAttemptStore) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+    a := models.LoginAttempt{Key: key}
+    err := pgxConn(ctx, s.db).QueryRow(ctx,
+        "SELECT failures, last_failure_at FROM login_attempts WHERE key=$1", key).
+        Scan(&a.Failures, &a.LastFailureAt)
+
+    if errors.Is(err, pgx.ErrNoRows) {
+        return &a, nil
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error fetching login attempts", "error", err)
+        return nil, err
+    }
+
+    return &a, nil
+}
+
+func (s *loginAttemptStore) Reserve(ctx context.Context, key string, limit models.LoginAttemptLimit) (*models.LoginAttempt, bool, error) {
+    now := time.Now()
+
+    a := models.LoginAttempt{Key: key}
+    err := pgxConn(ctx, s.db).QueryRow(ctx,
+        `INSERT INTO login_attempts (key, failures, last_failure_at) VALUES ($1, 1, $2)
+         ON CONFLICT (key) DO UPDATE SET
+             failures = CASE WHEN login_attempts.last_failure_at < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+             last_failure_at = EXCLUDED.last_failure_at,
+             previous_failure_at = login_attempts.last_failure_at
+         WHERE $4 <= 0 OR login_attempts.failures < $4
+             OR login_attempts.last_failure_at < $3 OR login_attempts.last_failure_at < $5
+         RETURNING failures, last_failure_at`,
+        key, now, now.Add(-limit.Window), limit.MaxFailures, now.Add(-limit.Lockout)).
+        Scan(&a.Failures, &a.LastFailureAt)
+
+    if errors.Is(err, pgx.ErrNoRows) {
+        current, err := s.Get(ctx, key)
+        return current, false, err
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error reserving login attempt", "error", err)
+        return nil, false, err
+    }
+
+    return &a, true, nil
+}
+
+func (s *loginAttemptStore) Release(ctx context.Context, key string) error {
+    _, err := pgxConn(ctx, s.db).Exec(ctx,
+        `UPDATE login_attempts SET failures = failures - 1,
+             last_failure_at = COALESCE(previous_failure_at, last_failure_at)
+         WHERE key=$1 AND failures > 0`, key)
+    if err != nil {
+        logging.Error(ctx, "error releasing login attempt", "error", err)
+    }
+
+    return err
+}
+
+func (s *loginAttemptStore) Reset(ctx context.Context, key string) error {
+    _, err := pgxConn(ctx, s.db).Exec(ctx, "DELETE FROM login_attempts WHERE key=$1", key)
+    if err != nil {
+        logging.Error(ctx, "error resetting login attempts", "error", err)
+    }
+
+    return err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+type memoryLoginAttempt struct {
+    models.LoginAttempt
+    // previousFailureAt is the LastFailureAt before the latest reservation,
+    // which Release puts back.
+    previousFailureAt time.Time
+}
+
+type memoryLoginAttemptStore struct {
+    mu       sync.Mutex
+    attempts map[string]memoryLoginAttempt
+}
+
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+    return &memoryLoginAttemptStore{
+        attempts: make(map[string]memoryLoginAttempt),
+    }
+}
+
+func (s *memoryLoginAttemptStore) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    a, ok := s.attempts[key]
+    if !ok {
+        return &models.LoginAttempt{Key: key}, nil
+    }
+    return &a.LoginAttempt, nil
+}
+
+func (s *memoryLoginAttemptStore) Reserve(ctx context.Context, key string, limit models.LoginAttemptLimit) (*models.LoginAttempt, bool, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    now := time.Now()
+    for k, a := range s.attempts {
+        if now.Sub(a.LastFailureAt) > limit.Window {
+            delete(s.attempts, k)
+        }
+    }
+
+    a, ok := s.attempts[key]
+    if !ok {
+        a = memoryLoginAttempt{LoginAttempt: models.LoginAttempt{Key: key}}
+    }
+
+    if limit.MaxFailures > 0 && a.Failures >= limit.MaxFailures && now.Sub(a.LastFailureAt) <= limit.Lockout {
+        return &a.LoginAttempt, false, nil
+    }
+
+    a.Failures++
+    a.previousFailureAt = a.LastFailureAt
+    a.LastFailureAt = now
+    s.attempts[key] = a
+
+    return &a.LoginAttempt, true, nil
+}
+
+func (s *memoryLoginAttemptStore) Release(ctx context.Context, key string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if a, ok := s.attempts[key]; ok && a.Failures > 0 {
+        a.Failures--
+        if !a.previousFailureAt.IsZero() {
+            a.LastFailureAt = a.previousFailureAt
+        }
+        s.attempts[key] = a
+    }
+    return nil
+}
+
+func (s *memoryLoginAttemptStore) Reset(ctx context.Context, key string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    delete(s.attempts, key)
+    return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+type sqliteLoginAttemptStore struct {
+    db *sql.DB
+}
+
+func NewSQLiteLoginAttemptStore(db *sql.DB) LoginAttemptStore {
+    return &sqliteLoginAttemptStore{db: db}
+}
+
+func (s *sqliteLoginAttemptStore) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+    a := models.LoginAttempt{Key: key}
+    err := sqliteConn(ctx, s.db).QueryRowContext(ctx,
+        "SELECT failures, last_failure_at FROM login_attempts WHERE key=?", key).
+        Scan(&a.Failures, &a.LastFailureAt)
+
+    if errors.Is(err, sql.ErrNoRows) {
+        return &a, nil
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error fetching login attempts", "error", err)
+        return nil, err
+    }
+
+    return &a, nil
+}
+
+func (s *sqliteLoginAttemptStore) Reserve(ctx context.Context, key string, limit models.LoginAttemptLimit) (*models.LoginAttempt, bool, error) {
+    now := dbNow()
+
+    a := models.LoginAttempt{Key: key}
+    err := sqliteConn(ctx, s.db).QueryRowContext(ctx,
+        `INSERT INTO login_attempts (key, failures, last_failure_at) VALUES (?1, 1, ?2)
+         ON CONFLICT (key) DO UPDATE SET
+             failures = CASE WHEN login_attempts.last_failure_at < ?3 THEN 1 ELSE login_attempts.failures + 1 END,
+             last_failure_at = excluded.last_failure_at,
+             previous_failure_at = login_attempts.last_failure_at
+         WHERE ?4 <= 0 OR login_attempts.failures < ?4
+             OR login_attempts.last_failure_at < ?3 OR login_attempts.last_failure_at < ?5
+         RETURNING failures, last_failure_at`,
+        key, now, now.Add(-limit.Window), limit.MaxFailures, now.Add(-limit.Lockout)).
+        Scan(&a.Failures, &a.LastFailureAt)
+
+    if errors.Is(err, sql.ErrNoRows) {
+        current, err := s.Get(ctx, key)
+        return current, false, err
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error reserving login attempt", "error", err)
+        return nil, false, err
+    }
+
+    return &a, true, nil
+}
+
+func (s *sqliteLoginAttemptStore) Release(ctx context.Context, key string) error {
+    _, err := sqliteConn(ctx, s.db).ExecContext(ctx,
+        `UPDATE login_attempts SET failures = failures - 1,
+             last_failure_at = COALESCE(previous_failure_at, last_failure_at)
+         WHERE key=? AND failures > 0`, key)
+    if err != nil {
+        logging.Error(ctx, "error releasing login attempt", "error", err)
+    }
+
+    return err
+}
+
+func (s *sqliteLoginAttemptStore) Reset(ctx context.Context, key string) error {
+    _, err := sqliteConn(ctx, s.db).ExecContext(ctx, "DELETE FROM login_attempts WHERE key=?", key)
+    if err != nil {
+        logging.Error(ctx, "error resetting login attempts", "error", err)
+    }
+
+    return err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+    "context"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+)
+
+var (
+    ErrRecoveryCodeNotFound = apperrors.NotFound("recovery code not found")
+)
+
+type RecoveryCodeRepository interface {
+    Replace(ctx context.Context, userID int64, codeHashes []string) error
+    Consume(ctx context.Context, userID int64, codeHash string) error
+    DeleteAll(ctx context.Context, userID int64) error
+}
+
+type recoveryCodeRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewRecoveryCodeRepository(db *pgxpool.Pool) RecoveryCodeRepository {
+    return &recoveryCodeRepository{db: db}
+}
+
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID int64, codeHashes []string) error {
+    logging.Debug(ctx, "replacing recovery codes", "user_id", userID)
+
+    _, err := pgxConn(ctx, r.db).Exec(ctx,
+        `WITH deleted AS (DELETE FROM recovery_codes WHERE user_id = $1)
+         INSERT INTO recovery_codes (user_id, code_hash) SELECT $1, UNNEST($2::text[])`,
+        userID, codeHashes)
+    if err != nil {
+        logging.Error(ctx, "error replacing recovery codes", "error", err)
+    }
+
+    return err
+}
+
+func (r *recoveryCodeRepository) Consume(ctx context.Context, userID int64, codeHash string) error {
+    result, err := pgxConn(ctx, r.db).Exec(ctx,
+        "UPDATE recovery_codes SET used_at = NOW() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL",
+        userID, codeHash)
+    if err != nil {
+        logging.Error(ctx, "error consuming recovery code", "error", err)
+        return err
+    }
+
+    if result.RowsAffected() == 0 {
+        return ErrRecoveryCodeNotFound
+    }
+
+    logging.Info(ctx, "recovery code used", "user_id", userID)
+    return nil
+}
+
+func (r *recoveryCodeRepository) DeleteAll(ctx context.Context, userID int64) error {
+    _, err := pgxConn(ctx, r.db).Exec(ctx, "DELETE FROM recovery_codes WHERE user_id=$1", userID)
+    if err != nil {
+        logging.Error(ctx, "error deleting recovery codes", "error", err)
+    }
+
+    return err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+    "context"
+    "sync"
+)
+
+type memoryRecoveryCodeRepository struct {
+    mu    sync.Mutex
+    codes map[int64]map[string]bool
+}
+
+func NewMemoryRecoveryCodeRepository() RecoveryCodeRepository {
+    return &memoryRecoveryCodeRepository{codes: make(map[int64]map[string]bool)}
+}
+
+func (r *memoryRecoveryCodeRepository) Replace(ctx context.Context, userID int64, codeHashes []string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    codes := make(map[string]bool, len(codeHashes))
+    for _, codeHash := range codeHashes {
+        codes[codeHash] = false
+    }
+    r.codes[userID] = codes
+    return nil
+}
+
+func (r *memoryRecoveryCodeRepository) Consume(ctx context.Context, userID int64, codeHash string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    used, ok := r.codes[userID][codeHash]
+    if !ok || used {
+        return ErrRecoveryCodeNotFound
+    }
+
+    r.codes[userID][codeHash] = true
+    return nil
+}
+
+func (r *memoryRecoveryCodeRepository) DeleteAll(ctx context.Context, userID int64) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    delete(r.codes, userID)
+    return nil
+}
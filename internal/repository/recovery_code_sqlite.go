@@ -0,0 +1,64 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+)
+
+type sqliteRecoveryCodeRepository struct {
+    db *sql.DB
+}
+
+func NewSQLiteRecoveryCodeRepository(db *sql.DB) RecoveryCodeRepository {
+    return &sqliteRecoveryCodeRepository{db: db}
+}
+
+func (r *sqliteRecoveryCodeRepository) Replace(ctx context.Context, userID int64, codeHashes []string) error {
+    logging.Debug(ctx, "replacing recovery codes", "user_id", userID)
+
+    // The delete and the inserts are separate statements, so the caller runs
+    // Replace within a transaction, as UserService does.
+    conn := sqliteConn(ctx, r.db)
+    if _, err := conn.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id=?", userID); err != nil {
+        logging.Error(ctx, "error replacing recovery codes", "error", err)
+        return err
+    }
+
+    for _, codeHash := range codeHashes {
+        _, err := conn.ExecContext(ctx, "INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)", userID, codeHash)
+        if err != nil {
+            logging.Error(ctx, "error replacing recovery codes", "error", err)
+            return err
+        }
+    }
+
+    return nil
+}
+
+func (r *sqliteRecoveryCodeRepository) Consume(ctx context.Context, userID int64, codeHash string) error {
+    result, err := sqliteConn(ctx, r.db).ExecContext(ctx,
+        "UPDATE recovery_codes SET used_at = ? WHERE user_id=? AND code_hash=? AND used_at IS NULL",
+        dbNow(), userID, codeHash)
+    if err != nil {
+        logging.Error(ctx, "error consuming recovery code", "error", err)
+        return err
+    }
+
+    if rowsAffected(result) == 0 {
+        return ErrRecoveryCodeNotFound
+    }
+
+    logging.Info(ctx, "recovery code used", "user_id", userID)
+    return nil
+}
+
+func (r *sqliteRecoveryCodeRepository) DeleteAll(ctx context.Context, userID int64) error {
+    _, err := sqliteConn(ctx, r.db).ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id=?", userID)
+    if err != nil {
+        logging.Error(ctx, "error deleting recovery codes", "error", err)
+    }
+
+    return err
+}
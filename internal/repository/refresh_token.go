@@ -0,0 +1,112 @@
+package repository
+
+import (
+    "context"
+    "errors"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+var (
+    ErrRefreshTokenNotFound = apperrors.NotFound("refresh token not found")
+)
+
+type RefreshTokenRepository interface {
+    Create(ctx context.Context, token *models.RefreshToken) error
+    GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+    MarkUsed(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+    RevokeFamily(ctx context.Context, familyID string) error
+    RevokeAllForUser(ctx context.Context, userID int64) error
+}
+
+type refreshTokenRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewRefreshTokenRepository(db *pgxpool.Pool) RefreshTokenRepository {
+    return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, t *models.RefreshToken) error {
+    logging.Debug(ctx, "creating refresh token", "user_id", t.UserID)
+
+    err := pgxConn(ctx, r.db).QueryRow(ctx,
+        "INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, mfa) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+        t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt, t.MFA).
+        Scan(&t.ID)
+    if err != nil {
+        logging.Error(ctx, "error creating refresh token", "error", err)
+    }
+
+    return err
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+    var t models.RefreshToken
+    err := pgxConn(ctx, r.db).QueryRow(ctx,
+        "SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, mfa FROM refresh_tokens WHERE token_hash=$1",
+        tokenHash).
+        Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.MFA)
+
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrRefreshTokenNotFound
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error fetching refresh token", "error", err)
+        return nil, err
+    }
+
+    return &t, nil
+}
+
+func (r *refreshTokenRepository) MarkUsed(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+    var t models.RefreshToken
+    err := pgxConn(ctx, r.db).QueryRow(ctx,
+        `UPDATE refresh_tokens SET used_at = NOW()
+         WHERE token_hash=$1 AND used_at IS NULL AND revoked_at IS NULL
+         RETURNING id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, mfa`,
+        tokenHash).
+        Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.MFA)
+
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrRefreshTokenNotFound
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error marking refresh token as used", "error", err)
+        return nil, err
+    }
+
+    return &t, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+    logging.Debug(ctx, "revoking refresh token family", "family_id", familyID)
+
+    _, err := pgxConn(ctx, r.db).Exec(ctx,
+        "UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id=$1 AND revoked_at IS NULL",
+        familyID)
+    if err != nil {
+        logging.Error(ctx, "error revoking refresh token family", "error", err)
+    }
+
+    return err
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+    logging.Debug(ctx, "revoking all refresh tokens", "user_id", userID)
+
+    _, err := pgxConn(ctx, r.db).Exec(ctx,
+        "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id=$1 AND revoked_at IS NULL",
+        userID)
+    if err != nil {
+        logging.Error(ctx, "error revoking refresh tokens", "error", err)
+    }
+
+    return err
+}
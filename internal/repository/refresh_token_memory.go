@@ -0,0 +1,82 @@
+package repository
+
+import (
+    "context"
+    "sync"
+
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+type memoryRefreshTokenRepository struct {
+    mu     sync.Mutex
+    nextID int64
+    tokens map[string]*models.RefreshToken
+}
+
+func NewMemoryRefreshTokenRepository() RefreshTokenRepository {
+    return &memoryRefreshTokenRepository{tokens: make(map[string]*models.RefreshToken)}
+}
+
+func (r *memoryRefreshTokenRepository) Create(ctx context.Context, t *models.RefreshToken) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.nextID++
+    t.ID = r.nextID
+
+    stored := *t
+    r.tokens[t.TokenHash] = &stored
+    return nil
+}
+
+func (r *memoryRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    t, ok := r.tokens[tokenHash]
+    if !ok {
+        return nil, ErrRefreshTokenNotFound
+    }
+
+    found := *t
+    return &found, nil
+}
+
+func (r *memoryRefreshTokenRepository) MarkUsed(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    t, ok := r.tokens[tokenHash]
+    if !ok || t.UsedAt != nil || t.RevokedAt != nil {
+        return nil, ErrRefreshTokenNotFound
+    }
+
+    now := dbNow()
+    t.UsedAt = &now
+
+    used := *t
+    return &used, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+    r.revoke(func(t *models.RefreshToken) bool { return t.FamilyID == familyID })
+    return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+    r.revoke(func(t *models.RefreshToken) bool { return t.UserID == userID })
+    return nil
+}
+
+func (r *memoryRefreshTokenRepository) revoke(match func(*models.RefreshToken) bool) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    now := dbNow()
+    for _, t := range r.tokens {
+        if t.RevokedAt == nil && match(t) {
+            revokedAt := now
+            t.RevokedAt = &revokedAt
+        }
+    }
+}
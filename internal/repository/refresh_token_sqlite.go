@@ -0,0 +1,98 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+type sqliteRefreshTokenRepository struct {
+    db *sql.DB
+}
+
+func NewSQLiteRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+    return &sqliteRefreshTokenRepository{db: db}
+}
+
+func (r *sqliteRefreshTokenRepository) Create(ctx context.Context, t *models.RefreshToken) error {
+    logging.Debug(ctx, "creating refresh token", "user_id", t.UserID)
+
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        "INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, mfa) VALUES (?, ?, ?, ?, ?) RETURNING id",
+        t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt.UTC(), t.MFA).
+        Scan(&t.ID)
+    if err != nil {
+        logging.Error(ctx, "error creating refresh token", "error", err)
+    }
+
+    return err
+}
+
+func (r *sqliteRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+    var t models.RefreshToken
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        "SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, mfa FROM refresh_tokens WHERE token_hash=?",
+        tokenHash).
+        Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.MFA)
+
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrRefreshTokenNotFound
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error fetching refresh token", "error", err)
+        return nil, err
+    }
+
+    return &t, nil
+}
+
+func (r *sqliteRefreshTokenRepository) MarkUsed(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+    var t models.RefreshToken
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        `UPDATE refresh_tokens SET used_at = ?
+         WHERE token_hash=? AND used_at IS NULL AND revoked_at IS NULL
+         RETURNING id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, mfa`,
+        dbNow(), tokenHash).
+        Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.MFA)
+
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrRefreshTokenNotFound
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error marking refresh token as used", "error", err)
+        return nil, err
+    }
+
+    return &t, nil
+}
+
+func (r *sqliteRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+    logging.Debug(ctx, "revoking refresh token family", "family_id", familyID)
+
+    _, err := sqliteConn(ctx, r.db).ExecContext(ctx,
+        "UPDATE refresh_tokens SET revoked_at = ? WHERE family_id=? AND revoked_at IS NULL",
+        dbNow(), familyID)
+    if err != nil {
+        logging.Error(ctx, "error revoking refresh token family", "error", err)
+    }
+
+    return err
+}
+
+func (r *sqliteRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+    logging.Debug(ctx, "revoking all refresh tokens", "user_id", userID)
+
+    _, err := sqliteConn(ctx, r.db).ExecContext(ctx,
+        "UPDATE refresh_tokens SET revoked_at = ? WHERE user_id=? AND revoked_at IS NULL",
+        dbNow(), userID)
+    if err != nil {
+        logging.Error(ctx, "error revoking refresh tokens", "error", err)
+    }
+
+    return err
+}
@@ -0,0 +1,394 @@
+package repositorytest
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+)
+
+// Stores is a set of repositories sharing one backend, so that tokens can
+// belong to users created through Users.
+type Stores struct {
+    Users         repository.UserRepository
+    RefreshTokens repository.RefreshTokenRepository
+    UserTokens    repository.UserTokenRepository
+    RecoveryCodes repository.RecoveryCodeRepository
+}
+
+// NewStores must return empty repositories. Like NewUserRepository it is
+// called once per subtest.
+type NewStores func(t *testing.T) Stores
+
+// TestRefreshTokenRepository runs the RefreshTokenRepository contract.
+func TestRefreshTokenRepository(t *testing.T, newStores NewStores) {
+    runStoreTests(t, newStores, []storeTest{
+        {"CreateAndGet", testRefreshTokenCreateAndGet},
+        {"MarkUsed", testRefreshTokenMarkUsed},
+        {"MarkUsedConcurrent", testRefreshTokenMarkUsedConcurrent},
+        {"RevokeFamily", testRefreshTokenRevokeFamily},
+        {"RevokeAllForUser", testRefreshTokenRevokeAllForUser},
+    })
+}
+
+// TestUserTokenRepository runs the UserTokenRepository contract.
+func TestUserTokenRepository(t *testing.T, newStores NewStores) {
+    runStoreTests(t, newStores, []storeTest{
+        {"Consume", testUserTokenConsume},
+        {"ConsumeExpired", testUserTokenConsumeExpired},
+        {"InvalidateAll", testUserTokenInvalidateAll},
+        {"LatestCreatedAt", testUserTokenLatestCreatedAt},
+    })
+}
+
+// TestRecoveryCodeRepository runs the RecoveryCodeRepository contract.
+func TestRecoveryCodeRepository(t *testing.T, newStores NewStores) {
+    runStoreTests(t, newStores, []storeTest{
+        {"Consume", testRecoveryCodeConsume},
+        {"Replace", testRecoveryCodeReplace},
+        {"DeleteAll", testRecoveryCodeDeleteAll},
+    })
+}
+
+type storeTest struct {
+    name string
+    run  func(t *testing.T, s Stores)
+}
+
+func runStoreTests(t *testing.T, newStores NewStores, tests []storeTest) {
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            tt.run(t, newStores(t))
+        })
+    }
+}
+
+func createRefreshToken(t *testing.T, s Stores, userID int64, familyID, hash string) *models.RefreshToken {
+    t.Helper()
+
+    token := &models.RefreshToken{
+        UserID:    userID,
+        FamilyID:  familyID,
+        TokenHash: hash,
+        ExpiresAt: time.Now().Add(time.Hour),
+    }
+    if err := s.RefreshTokens.Create(context.Background(), token); err != nil {
+        t.Fatalf("Create(%s): %v", hash, err)
+    }
+    return token
+}
+
+func getRefreshToken(t *testing.T, s Stores, hash string) *models.RefreshToken {
+    t.Helper()
+
+    token, err := s.RefreshTokens.GetByHash(context.Background(), hash)
+    if err != nil {
+        t.Fatalf("GetByHash(%s): %v", hash, err)
+    }
+    return token
+}
+
+func testRefreshTokenCreateAndGet(t *testing.T, s Stores) {
+    ctx := context.Background()
+    u := createUser(t, s.Users, "Alice", "alice@example.com")
+
+    expiresAt := time.Now().Add(time.Hour)
+    token := &models.RefreshToken{UserID: u.ID, FamilyID: "family", TokenHash: "hash", ExpiresAt: expiresAt, MFA: true}
+    if err := s.RefreshTokens.Create(ctx, token); err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+    if token.ID == 0 {
+        t.Error("Create did not assign an ID")
+    }
+
+    stored := getRefreshToken(t, s, "hash")
+    if stored.ID != token.ID || stored.UserID != u.ID || stored.FamilyID != "family" || !stored.MFA {
+        t.Errorf("stored token = %+v", stored)
+    }
+    if d := stored.ExpiresAt.Sub(expiresAt); d < -time.Second || d > time.Second {
+        t.Errorf("ExpiresAt = %v, want %v", stored.ExpiresAt, expiresAt)
+    }
+    if stored.UsedAt != nil || stored.RevokedAt != nil {
+        t.Errorf("new token is used or revoked: %+v", stored)
+    }
+
+    _, err := s.RefreshTokens.GetByHash(ctx, "missing")
+    expectError(t, "GetByHash of a missing token", err, repository.ErrRefreshTokenNotFound)
+}
+
+func testRefreshTokenMarkUsed(t *testing.T, s Stores) {
+    ctx := context.Background()
+    u := createUser(t, s.Users, "Alice", "alice@example.com")
+    token := createRefreshToken(t, s, u.ID, "family", "hash")
+
+    used, err := s.RefreshTokens.MarkUsed(ctx, "hash")
+    if err != nil {
+        t.Fatalf("MarkUsed: %v", err)
+    }
+    if used.ID != token.ID || used.UsedAt == nil {
+        t.Errorf("MarkUsed = %+v", used)
+    }
+    if getRefreshToken(t, s, "hash").UsedAt == nil {
+        t.Error("MarkUsed did not store UsedAt")
+    }
+
+    _, err = s.RefreshTokens.MarkUsed(ctx, "hash")
+    expectError(t, "MarkUsed of a used token", err, repository.ErrRefreshTokenNotFound)
+
+    _, err = s.RefreshTokens.MarkUsed(ctx, "missing")
+    expectError(t, "MarkUsed of a missing token", err, repository.ErrRefreshTokenNotFound)
+}
+
+func testRefreshTokenMarkUsedConcurrent(t *testing.T, s Stores) {
+    u := createUser(t, s.Users, "Alice", "alice@example.com")
+    createRefreshToken(t, s, u.ID, "family", "hash")
+
+    const n = 10
+    var wg sync.WaitGroup
+    errs := make(chan error, n)
+    for i := 0; i < n; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            _, err := s.RefreshTokens.MarkUsed(context.Background(), "hash")
+            errs <- err
+        }()
+    }
+    wg.Wait()
+    close(errs)
+
+    succeeded := 0
+    for err := range errs {
+        switch {
+        case err == nil:
+            succeeded++
+        case !errors.Is(err, repository.ErrRefreshTokenNotFound):
+            t.Errorf("concurrent MarkUsed: %v", err)
+        }
+    }
+    if succeeded != 1 {
+        t.Errorf("%d concurrent MarkUsed calls succeeded, want 1", succeeded)
+    }
+}
+
+func testRefreshTokenRevokeFamily(t *testing.T, s Stores) {
+    ctx := context.Background()
+    u := createUser(t, s.Users, "Alice", "alice@example.com")
+    createRefreshToken(t, s, u.ID, "revoked", "a")
+    createRefreshToken(t, s, u.ID, "revoked", "b")
+    createRefreshToken(t, s, u.ID, "kept", "c")
+
+    if err := s.RefreshTokens.RevokeFamily(ctx, "revoked"); err != nil {
+        t.Fatalf("RevokeFamily: %v", err)
+    }
+
+    for _, hash := range []string{"a", "b"} {
+        if getRefreshToken(t, s, hash).RevokedAt == nil {
+            t.Errorf("token %s was not revoked", hash)
+        }
+        _, err := s.RefreshTokens.MarkUsed(ctx, hash)
+        expectError(t, "MarkUsed of a revoked token", err, repository.ErrRefreshTokenNotFound)
+    }
+
+    if getRefreshToken(t, s, "c").RevokedAt != nil {
+        t.Error("token of another family was revoked")
+    }
+    if _, err := s.RefreshTokens.MarkUsed(ctx, "c"); err != nil {
+        t.Errorf("MarkUsed of a token of another family: %v", err)
+    }
+}
+
+func testRefreshTokenRevokeAllForUser(t *testing.T, s Stores) {
+    ctx := context.Background()
+    alice := createUser(t, s.Users, "Alice", "alice@example.com")
+    bob := createUser(t, s.Users, "Bob", "bob@example.com")
+    createRefreshToken(t, s, alice.ID, "alice-1", "a")
+    createRefreshToken(t, s, alice.ID, "alice-2", "b")
+    createRefreshToken(t, s, bob.ID, "bob", "c")
+
+    if err := s.RefreshTokens.RevokeAllForUser(ctx, alice.ID); err != nil {
+        t.Fatalf("RevokeAllForUser: %v", err)
+    }
+
+    for _, hash := range []string{"a", "b"} {
+        if getRefreshToken(t, s, hash).RevokedAt == nil {
+            t.Errorf("token %s was not revoked", hash)
+        }
+    }
+    if getRefreshToken(t, s, "c").RevokedAt != nil {
+        t.Error("token of another user was revoked")
+    }
+}
+
+func createUserToken(t *testing.T, s Stores, userID int64, purpose, hash string, expiresIn time.Duration) {
+    t.Helper()
+
+    token := &models.UserToken{
+        UserID:    userID,
+        Purpose:   purpose,
+        TokenHash: hash,
+        ExpiresAt: time.Now().Add(expiresIn),
+    }
+    if err := s.UserTokens.Create(context.Background(), token); err != nil {
+        t.Fatalf("Create(%s): %v", hash, err)
+    }
+    if token.ID == 0 || token.CreatedAt.IsZero() {
+        t.Errorf("Create did not set ID and CreatedAt: %+v", token)
+    }
+}
+
+func testUserTokenConsume(t *testing.T, s Stores) {
+    ctx := context.Background()
+    u := createUser(t, s.Users, "Alice", "alice@example.com")
+    createUserToken(t, s, u.ID, models.TokenPurposePasswordReset, "hash", time.Hour)
+
+    _, err := s.UserTokens.Consume(ctx, "hash", models.TokenPurposeEmailVerification)
+    expectError(t, "Consume for another purpose", err, repository.ErrUserTokenNotFound)
+
+    consumed, err := s.UserTokens.Consume(ctx, "hash", models.TokenPurposePasswordReset)
+    if err != nil {
+        t.Fatalf("Consume: %v", err)
+    }
+    if consumed.UserID != u.ID || consumed.UsedAt == nil {
+        t.Errorf("Consume = %+v", consumed)
+    }
+
+    _, err = s.UserTokens.Consume(ctx, "hash", models.TokenPurposePasswordReset)
+    expectError(t, "Consume of a used token", err, repository.ErrUserTokenNotFound)
+
+    _, err = s.UserTokens.Consume(ctx, "missing", models.TokenPurposePasswordReset)
+    expectError(t, "Consume of a missing token", err, repository.ErrUserTokenNotFound)
+}
+
+func testUserTokenConsumeExpired(t *testing.T, s Stores) {
+    u := createUser(t, s.Users, "Alice", "alice@example.com")
+    createUserToken(t, s, u.ID, models.TokenPurposePasswordReset, "hash", -time.Minute)
+
+    _, err := s.UserTokens.Consume(context.Background(), "hash", models.TokenPurposePasswordReset)
+    expectError(t, "Consume of an expired token", err, repository.ErrUserTokenNotFound)
+}
+
+func testUserTokenInvalidateAll(t *testing.T, s Stores) {
+    ctx := context.Background()
+    alice := createUser(t, s.Users, "Alice", "alice@example.com")
+    bob := createUser(t, s.Users, "Bob", "bob@example.com")
+    createUserToken(t, s, alice.ID, models.TokenPurposePasswordReset, "a", time.Hour)
+    createUserToken(t, s, alice.ID, models.TokenPurposePasswordReset, "b", time.Hour)
+    createUserToken(t, s, alice.ID, models.TokenPurposeEmailVerification, "c", time.Hour)
+    createUserToken(t, s, bob.ID, models.TokenPurposePasswordReset, "d", time.Hour)
+
+    if err := s.UserTokens.InvalidateAll(ctx, alice.ID, models.TokenPurposePasswordReset); err != nil {
+        t.Fatalf("InvalidateAll: %v", err)
+    }
+
+    for _, hash := range []string{"a", "b"} {
+        _, err := s.UserTokens.Consume(ctx, hash, models.TokenPurposePasswordReset)
+        expectError(t, "Consume of an invalidated token", err, repository.ErrUserTokenNotFound)
+    }
+    if _, err := s.UserTokens.Consume(ctx, "c", models.TokenPurposeEmailVerification); err != nil {
+        t.Errorf("Consume of a token for another purpose: %v", err)
+    }
+    if _, err := s.UserTokens.Consume(ctx, "d", models.TokenPurposePasswordReset); err != nil {
+        t.Errorf("Consume of a token of another user: %v", err)
+    }
+}
+
+func testUserTokenLatestCreatedAt(t *testing.T, s Stores) {
+    ctx := context.Background()
+    u := createUser(t, s.Users, "Alice", "alice@example.com")
+
+    latest, err := s.UserTokens.LatestCreatedAt(ctx, u.ID, models.TokenPurposePasswordReset)
+    if err != nil {
+        t.Fatalf("LatestCreatedAt: %v", err)
+    }
+    if !latest.IsZero() {
+        t.Errorf("LatestCreatedAt without tokens = %v, want zero", latest)
+    }
+
+    createUserToken(t, s, u.ID, models.TokenPurposePasswordReset, "hash", time.Hour)
+
+    latest, err = s.UserTokens.LatestCreatedAt(ctx, u.ID, models.TokenPurposePasswordReset)
+    if err != nil {
+        t.Fatalf("LatestCreatedAt: %v", err)
+    }
+    if d := time.Since(latest); d < -time.Second || d > time.Minute {
+        t.Errorf("LatestCreatedAt = %v, want about now", latest)
+    }
+
+    latest, err = s.UserTokens.LatestCreatedAt(ctx, u.ID, models.TokenPurposeEmailVerification)
+    if err != nil {
+        t.Fatalf("LatestCreatedAt: %v", err)
+    }
+    if !latest.IsZero() {
+        t.Errorf("LatestCreatedAt for another purpose = %v, want zero", latest)
+    }
+}
+
+func testRecoveryCodeConsume(t *testing.T, s Stores) {
+    ctx := context.Background()
+    alice := createUser(t, s.Users, "Alice", "alice@example.com")
+    bob := createUser(t, s.Users, "Bob", "bob@example.com")
+
+    if err := s.RecoveryCodes.Replace(ctx, alice.ID, []string{"a", "b"}); err != nil {
+        t.Fatalf("Replace: %v", err)
+    }
+
+    if err := s.RecoveryCodes.Consume(ctx, alice.ID, "a"); err != nil {
+        t.Fatalf("Consume: %v", err)
+    }
+    expectError(t, "Consume of a used code", s.RecoveryCodes.Consume(ctx, alice.ID, "a"), repository.ErrRecoveryCodeNotFound)
+    expectError(t, "Consume of a missing code", s.RecoveryCodes.Consume(ctx, alice.ID, "c"), repository.ErrRecoveryCodeNotFound)
+    expectError(t, "Consume of another user's code", s.RecoveryCodes.Consume(ctx, bob.ID, "b"), repository.ErrRecoveryCodeNotFound)
+
+    if err := s.RecoveryCodes.Consume(ctx, alice.ID, "b"); err != nil {
+        t.Errorf("Consume of the second code: %v", err)
+    }
+}
+
+func testRecoveryCodeReplace(t *testing.T, s Stores) {
+    ctx := context.Background()
+    u := createUser(t, s.Users, "Alice", "alice@example.com")
+
+    if err := s.RecoveryCodes.Replace(ctx, u.ID, []string{"a", "b"}); err != nil {
+        t.Fatalf("Replace: %v", err)
+    }
+    if err := s.RecoveryCodes.Consume(ctx, u.ID, "a"); err != nil {
+        t.Fatalf("Consume: %v", err)
+    }
+
+    if err := s.RecoveryCodes.Replace(ctx, u.ID, []string{"a", "c"}); err != nil {
+        t.Fatalf("Replace: %v", err)
+    }
+
+    expectError(t, "Consume of a replaced code", s.RecoveryCodes.Consume(ctx, u.ID, "b"), repository.ErrRecoveryCodeNotFound)
+    for _, code := range []string{"a", "c"} {
+        if err := s.RecoveryCodes.Consume(ctx, u.ID, code); err != nil {
+            t.Errorf("Consume(%s) after Replace: %v", code, err)
+        }
+    }
+}
+
+func testRecoveryCodeDeleteAll(t *testing.T, s Stores) {
+    ctx := context.Background()
+    alice := createUser(t, s.Users, "Alice", "alice@example.com")
+    bob := createUser(t, s.Users, "Bob", "bob@example.com")
+
+    for _, u := range []*models.User{alice, bob} {
+        if err := s.RecoveryCodes.Replace(ctx, u.ID, []string{"a"}); err != nil {
+            t.Fatalf("Replace: %v", err)
+        }
+    }
+
+    if err := s.RecoveryCodes.DeleteAll(ctx, alice.ID); err != nil {
+        t.Fatalf("DeleteAll: %v", err)
+    }
+
+    expectError(t, "Consume after DeleteAll", s.RecoveryCodes.Consume(ctx, alice.ID, "a"), repository.ErrRecoveryCodeNotFound)
+    if err := s.RecoveryCodes.Consume(ctx, bob.ID, "a"); err != nil {
+        t.Errorf("Consume of another user's code after DeleteAll: %v", err)
+    }
+}
@@ -0,0 +1,718 @@
+// Package repositorytest holds conformance suites that every repository
+// implementation is expected to pass.
+package repositorytest
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+// NewUserRepository must return an empty repository. It is called once per
+// subtest, so implementations backed by a database should clear the users
+// table before returning.
+type NewUserRepository func(t *testing.T) repository.UserRepository
+
+// TestUserRepository runs the UserRepository contract against the
+// repositories returned by newRepo:
+//
+//    func TestMemoryUserRepository(t *testing.T) {
+//        repositorytest.TestUserRepository(t, func(t *testing.T) repository.UserRepository {
+//            return repository.NewMemoryUserRepository()
+//        })
+//    }
+func TestUserRepository(t *testing.T, newRepo NewUserRepository) {
+    tests := []struct {
+        name string
+        run  func(t *testing.T, repo repository.UserRepository)
+    }{
+        {"Create", testCreate},
+        {"CreateDuplicateEmail", testCreateDuplicateEmail},
+        {"CreateConcurrent", testCreateConcurrent},
+        {"Get", testGet},
+        {"Update", testUpdate},
+        {"UpdateConflicts", testUpdateConflicts},
+        {"Patch", testPatch},
+        {"DeleteAndRestore", testDeleteAndRestore},
+        {"Purge", testPurge},
+        {"RecordLogin", testRecordLogin},
+        {"UpdateRoleAndPassword", testUpdateRoleAndPassword},
+        {"MarkEmailVerified", testMarkEmailVerified},
+        {"EmailChangeResetsVerification", testEmailChangeResetsVerification},
+        {"TOTP", testTOTP},
+        {"ChangesBumpVersion", testChangesBumpVersion},
+        {"ListFilters", testListFilters},
+        {"ListPagination", testListPagination},
+        {"ListErrors", testListErrors},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            tt.run(t, newRepo(t))
+        })
+    }
+}
+
+func createUser(t *testing.T, repo repository.UserRepository, name, email string) *models.User {
+    t.Helper()
+
+    u := &models.User{Name: name, Email: email, Password: "secret123"}
+    if err := repo.Create(context.Background(), u); err != nil {
+        t.Fatalf("Create(%s): %v", email, err)
+    }
+    return u
+}
+
+func getUser(t *testing.T, repo repository.UserRepository, id int64) *models.User {
+    t.Helper()
+
+    u, err := repo.GetByID(context.Background(), id)
+    if err != nil {
+        t.Fatalf("GetByID(%d): %v", id, err)
+    }
+    return u
+}
+
+func expectError(t *testing.T, op string, err, want error) {
+    t.Helper()
+
+    if !errors.Is(err, want) {
+        t.Fatalf("%s: got error %v, want %v", op, err, want)
+    }
+}
+
+func testCreate(t *testing.T, repo repository.UserRepository) {
+    u := createUser(t, repo, "Alice", "alice@example.com")
+
+    if u.ID == 0 {
+        t.Error("Create did not assign an ID")
+    }
+    if u.Role != models.RoleUser {
+        t.Errorf("Role = %q, want %q", u.Role, models.RoleUser)
+    }
+    if u.Version != 1 {
+        t.Errorf("Version = %d, want 1", u.Version)
+    }
+    if u.CreatedAt.IsZero() || !u.UpdatedAt.Equal(u.CreatedAt) {
+        t.Errorf("timestamps not set: created %v, updated %v", u.CreatedAt, u.UpdatedAt)
+    }
+    if u.Password == "secret123" || !utils.CheckPasswordHash("secret123", u.Password) {
+        t.Error("Create did not replace the password with its hash")
+    }
+
+    stored := getUser(t, repo, u.ID)
+    if stored.Name != "Alice" || stored.Email != "alice@example.com" || stored.Password != u.Password {
+        t.Errorf("stored user = %+v, want %+v", stored, u)
+    }
+    if stored.EmailVerifiedAt != nil || stored.TOTPEnabledAt != nil || stored.LastLoginAt != nil || stored.DeletedAt != nil {
+        t.Errorf("new user has unexpected state: %+v", stored)
+    }
+
+    admin := &models.User{Name: "Admin", Email: "admin@example.com", Password: "secret123", Role: models.RoleAdmin}
+    if err := repo.Create(context.Background(), admin); err != nil {
+        t.Fatalf("Create(admin): %v", err)
+    }
+    if got := getUser(t, repo, admin.ID).Role; got != models.RoleAdmin {
+        t.Errorf("Role = %q, want %q", got, models.RoleAdmin)
+    }
+}
+
+func testCreateDuplicateEmail(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "alice@example.com")
+
+    err := repo.Create(ctx, &models.User{Name: "Other", Email: "ALICE@example.com", Password: "secret123"})
+    expectError(t, "Create with a differently cased email", err, repository.ErrUserExists)
+
+    if err := repo.Delete(ctx, u.ID, nil); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    err = repo.Create(ctx, &models.User{Name: "Other", Email: "alice@example.com", Password: "secret123"})
+    expectError(t, "Create with the email of a deleted user", err, repository.ErrUserExists)
+}
+
+func testCreateConcurrent(t *testing.T, repo repository.UserRepository) {
+    const workers = 8
+
+    var wg sync.WaitGroup
+    errs := make(chan error, workers)
+    for i := 0; i < workers; i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            errs <- repo.Create(context.Background(), &models.User{
+                Name:     fmt.Sprintf("User %d", i),
+                Email:    "race@example.com",
+                Password: "secret123",
+            })
+        }(i)
+    }
+    wg.Wait()
+    close(errs)
+
+    var created int
+    for err := range errs {
+        switch {
+        case err == nil:
+            created++
+        case !errors.Is(err, repository.ErrUserExists):
+            t.Errorf("Create: unexpected error %v", err)
+        }
+    }
+    if created != 1 {
+        t.Errorf("%d concurrent creates succeeded, want 1", created)
+    }
+}
+
+func testGet(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "Alice@Example.com")
+
+    byEmail, err := repo.GetByEmail(ctx, "alice@example.COM")
+    if err != nil {
+        t.Fatalf("GetByEmail: %v", err)
+    }
+    if byEmail.ID != u.ID || byEmail.Email != "Alice@Example.com" {
+        t.Errorf("GetByEmail = %+v, want user %d with the original email", byEmail, u.ID)
+    }
+
+    _, err = repo.GetByID(ctx, u.ID+1000)
+    expectError(t, "GetByID of a missing user", err, repository.ErrUserNotFound)
+
+    _, err = repo.GetByEmail(ctx, "missing@example.com")
+    expectError(t, "GetByEmail of a missing user", err, repository.ErrUserNotFound)
+
+    fetched := getUser(t, repo, u.ID)
+    fetched.Name = "Changed"
+    if getUser(t, repo, u.ID).Name != "Alice" {
+        t.Error("modifying a returned user changed the stored user")
+    }
+}
+
+func testUpdate(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "alice@example.com")
+
+    update := &models.User{ID: u.ID, Name: "Alice Smith", Email: "smith@example.com"}
+    if err := repo.Update(ctx, update, []int64{u.Version}); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    if update.Version != u.Version+1 {
+        t.Errorf("Version = %d, want %d", update.Version, u.Version+1)
+    }
+
+    stored := getUser(t, repo, u.ID)
+    if stored.Name != "Alice Smith" || stored.Email != "smith@example.com" || stored.Version != update.Version {
+        t.Errorf("stored user = %+v after update", stored)
+    }
+    if stored.UpdatedAt.Before(u.UpdatedAt) {
+        t.Errorf("UpdatedAt went backwards: %v before %v", stored.UpdatedAt, u.UpdatedAt)
+    }
+
+    if _, err := repo.GetByEmail(ctx, "alice@example.com"); !errors.Is(err, repository.ErrUserNotFound) {
+        t.Errorf("old email still resolves: %v", err)
+    }
+    createUser(t, repo, "New Alice", "alice@example.com")
+
+    if err := repo.Update(ctx, &models.User{ID: u.ID, Name: "Alice", Email: "SMITH@example.com"}, nil); err != nil {
+        t.Errorf("Update to a differently cased own email: %v", err)
+    }
+}
+
+func testUpdateConflicts(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    alice := createUser(t, repo, "Alice", "alice@example.com")
+    createUser(t, repo, "Bob", "bob@example.com")
+
+    err := repo.Update(ctx, &models.User{ID: alice.ID, Name: "Alice", Email: "Bob@example.com"}, nil)
+    expectError(t, "Update to a taken email", err, repository.ErrUserExists)
+
+    err = repo.Update(ctx, &models.User{ID: alice.ID, Name: "Alice", Email: "alice@example.com"}, []int64{alice.Version + 1})
+    expectError(t, "Update with a stale version", err, repository.ErrVersionMismatch)
+
+    err = repo.Update(ctx, &models.User{ID: alice.ID + 1000, Name: "Ghost", Email: "ghost@example.com"}, []int64{1})
+    expectError(t, "Update of a missing user", err, repository.ErrUserNotFound)
+
+    if got := getUser(t, repo, alice.ID); got.Version != alice.Version || got.Email != "alice@example.com" {
+        t.Errorf("failed updates changed the user: %+v", got)
+    }
+}
+
+func testPatch(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "alice@example.com")
+    createUser(t, repo, "Bob", "bob@example.com")
+
+    name := "Alice Smith"
+    version, err := repo.Patch(ctx, u.ID, &models.UserPatch{Name: &name}, []int64{u.Version})
+    if err != nil {
+        t.Fatalf("Patch: %v", err)
+    }
+    if version != u.Version+1 {
+        t.Errorf("Patch version = %d, want %d", version, u.Version+1)
+    }
+
+    stored := getUser(t, repo, u.ID)
+    if stored.Name != name || stored.Email != "alice@example.com" {
+        t.Errorf("stored user = %+v after patching the name", stored)
+    }
+
+    email := "bob@example.com"
+    _, err = repo.Patch(ctx, u.ID, &models.UserPatch{Email: &email}, nil)
+    expectError(t, "Patch to a taken email", err, repository.ErrUserExists)
+
+    _, err = repo.Patch(ctx, u.ID, &models.UserPatch{Name: &name}, []int64{u.Version})
+    expectError(t, "Patch with a stale version", err, repository.ErrVersionMismatch)
+
+    _, err = repo.Patch(ctx, u.ID+1000, &models.UserPatch{Name: &name}, nil)
+    expectError(t, "Patch of a missing user", err, repository.ErrUserNotFound)
+}
+
+func testDeleteAndRestore(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "alice@example.com")
+
+    err := repo.Delete(ctx, u.ID, []int64{u.Version + 1})
+    expectError(t, "Delete with a stale version", err, repository.ErrVersionMismatch)
+
+    if err := repo.Delete(ctx, u.ID, []int64{u.Version}); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+
+    _, err = repo.GetByID(ctx, u.ID)
+    expectError(t, "GetByID of a deleted user", err, repository.ErrUserNotFound)
+    _, err = repo.GetByEmail(ctx, u.Email)
+    expectError(t, "GetByEmail of a deleted user", err, repository.ErrUserNotFound)
+    expectError(t, "Delete of a deleted user", repo.Delete(ctx, u.ID, nil), repository.ErrUserNotFound)
+    expectError(t, "UpdateRole of a deleted user", repo.UpdateRole(ctx, u.ID, models.RoleAdmin), repository.ErrUserNotFound)
+
+    page, err := repo.List(ctx, &models.UserListParams{Limit: 10, Sort: "id", IncludeDeleted: true})
+    if err != nil {
+        t.Fatalf("List: %v", err)
+    }
+    if len(page.Users) != 1 || page.Users[0].DeletedAt == nil {
+        t.Fatalf("List with deleted users = %+v, want the deleted user", page.Users)
+    }
+
+    if err := repo.Restore(ctx, u.ID); err != nil {
+        t.Fatalf("Restore: %v", err)
+    }
+    restored := getUser(t, repo, u.ID)
+    if restored.DeletedAt != nil || restored.Version != u.Version+2 {
+        t.Errorf("restored user = %+v, want no deleted_at and version %d", restored, u.Version+2)
+    }
+
+    expectError(t, "Restore of an active user", repo.Restore(ctx, u.ID), repository.ErrUserNotFound)
+    expectError(t, "Restore of a missing user", repo.Restore(ctx, u.ID+1000), repository.ErrUserNotFound)
+}
+
+func testPurge(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    deleted := createUser(t, repo, "Alice", "alice@example.com")
+    active := createUser(t, repo, "Bob", "bob@example.com")
+
+    if err := repo.Delete(ctx, deleted.ID, nil); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+
+    purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+    if err != nil {
+        t.Fatalf("Purge: %v", err)
+    }
+    if purged != 0 {
+        t.Errorf("Purge before the deletion removed %d users, want 0", purged)
+    }
+
+    purged, err = repo.Purge(ctx, time.Now().Add(time.Hour))
+    if err != nil {
+        t.Fatalf("Purge: %v", err)
+    }
+    if purged != 1 {
+        t.Errorf("Purge removed %d users, want 1", purged)
+    }
+
+    expectError(t, "Restore of a purged user", repo.Restore(ctx, deleted.ID), repository.ErrUserNotFound)
+    getUser(t, repo, active.ID)
+    createUser(t, repo, "New Alice", "alice@example.com")
+}
+
+func testRecordLogin(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "alice@example.com")
+
+    if err := repo.RecordLogin(ctx, u.ID); err != nil {
+        t.Fatalf("RecordLogin: %v", err)
+    }
+
+    stored := getUser(t, repo, u.ID)
+    if stored.LastLoginAt == nil {
+        t.Fatal("RecordLogin did not set LastLoginAt")
+    }
+    if stored.Version != u.Version {
+        t.Errorf("Version = %d, want %d: a login must not fail If-Match", stored.Version, u.Version)
+    }
+
+    if err := repo.RecordLogin(ctx, u.ID+1000); err != nil {
+        t.Errorf("RecordLogin of a missing user: %v", err)
+    }
+}
+
+func testUpdateRoleAndPassword(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "alice@example.com")
+
+    if err := repo.UpdateRole(ctx, u.ID, models.RoleAdmin); err != nil {
+        t.Fatalf("UpdateRole: %v", err)
+    }
+    stored := getUser(t, repo, u.ID)
+    if stored.Role != models.RoleAdmin || stored.Version != u.Version+1 {
+        t.Errorf("after UpdateRole: role %q version %d", stored.Role, stored.Version)
+    }
+
+    if err := repo.UpdatePassword(ctx, u.ID, "newsecret456"); err != nil {
+        t.Fatalf("UpdatePassword: %v", err)
+    }
+    stored = getUser(t, repo, u.ID)
+    if !utils.CheckPasswordHash("newsecret456", stored.Password) {
+        t.Error("UpdatePassword did not store a hash of the new password")
+    }
+
+    expectError(t, "UpdateRole of a missing user", repo.UpdateRole(ctx, u.ID+1000, models.RoleAdmin), repository.ErrUserNotFound)
+    expectError(t, "UpdatePassword of a missing user", repo.UpdatePassword(ctx, u.ID+1000, "newsecret456"), repository.ErrUserNotFound)
+}
+
+func testMarkEmailVerified(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "alice@example.com")
+
+    if err := repo.MarkEmailVerified(ctx, u.ID); err != nil {
+        t.Fatalf("MarkEmailVerified: %v", err)
+    }
+    first := getUser(t, repo, u.ID).EmailVerifiedAt
+    if first == nil {
+        t.Fatal("MarkEmailVerified did not set EmailVerifiedAt")
+    }
+
+    if err := repo.MarkEmailVerified(ctx, u.ID); err != nil {
+        t.Fatalf("MarkEmailVerified: %v", err)
+    }
+    if second := getUser(t, repo, u.ID).EmailVerifiedAt; second == nil || !second.Equal(*first) {
+        t.Errorf("EmailVerifiedAt changed on repeated verification: %v -> %v", first, second)
+    }
+
+    expectError(t, "MarkEmailVerified of a missing user", repo.MarkEmailVerified(ctx, u.ID+1000), repository.ErrUserNotFound)
+}
+
+func testEmailChangeResetsVerification(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "alice@example.com")
+    verify := func() {
+        t.Helper()
+        if err := repo.MarkEmailVerified(ctx, u.ID); err != nil {
+            t.Fatalf("MarkEmailVerified: %v", err)
+        }
+    }
+
+    verify()
+    if err := repo.Update(ctx, &models.User{ID: u.ID, Name: "Alice Smith", Email: "alice@example.com"}, nil); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    if getUser(t, repo, u.ID).EmailVerifiedAt == nil {
+        t.Error("Update with the same email reset the verification")
+    }
+
+    if err := repo.Update(ctx, &models.User{ID: u.ID, Name: "Alice Smith", Email: "Alice@Example.com"}, nil); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    if getUser(t, repo, u.ID).EmailVerifiedAt == nil {
+        t.Error("Update that only changed the case of the email reset the verification")
+    }
+
+    if err := repo.Update(ctx, &models.User{ID: u.ID, Name: "Alice", Email: "smith@example.com"}, nil); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    if got := getUser(t, repo, u.ID).EmailVerifiedAt; got != nil {
+        t.Errorf("EmailVerifiedAt = %v after Update changed the email, want nil", got)
+    }
+
+    verify()
+    name := "Alice Smith"
+    if _, err := repo.Patch(ctx, u.ID, &models.UserPatch{Name: &name}, nil); err != nil {
+        t.Fatalf("Patch: %v", err)
+    }
+    if getUser(t, repo, u.ID).EmailVerifiedAt == nil {
+        t.Error("Patch of the name reset the verification")
+    }
+
+    recased := "Smith@Example.com"
+    if _, err := repo.Patch(ctx, u.ID, &models.UserPatch{Email: &recased}, nil); err != nil {
+        t.Fatalf("Patch: %v", err)
+    }
+    if getUser(t, repo, u.ID).EmailVerifiedAt == nil {
+        t.Error("Patch that only changed the case of the email reset the verification")
+    }
+
+    email := "alice@example.com"
+    if _, err := repo.Patch(ctx, u.ID, &models.UserPatch{Email: &email}, nil); err != nil {
+        t.Fatalf("Patch: %v", err)
+    }
+    if got := getUser(t, repo, u.ID).EmailVerifiedAt; got != nil {
+        t.Errorf("EmailVerifiedAt = %v after Patch changed the email, want nil", got)
+    }
+}
+
+func testTOTP(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "alice@example.com")
+
+    expectError(t, "EnableTOTP without a secret", repo.EnableTOTP(ctx, u.ID), repository.ErrUserNotFound)
+
+    if err := repo.SetTOTPSecret(ctx, u.ID, "JBSWY3DPEHPK3PXP"); err != nil {
+        t.Fatalf("SetTOTPSecret: %v", err)
+    }
+    if err := repo.EnableTOTP(ctx, u.ID); err != nil {
+        t.Fatalf("EnableTOTP: %v", err)
+    }
+
+    stored := getUser(t, repo, u.ID)
+    if stored.TOTPSecret != "JBSWY3DPEHPK3PXP" || stored.TOTPEnabledAt == nil {
+        t.Errorf("after EnableTOTP: secret %q enabled %v", stored.TOTPSecret, stored.TOTPEnabledAt)
+    }
+
+    for _, step := range []struct {
+        value int64
+        want  bool
+    }{{10, true}, {10, false}, {9, false}, {11, true}} {
+        ok, err := repo.UpdateTOTPLastStep(ctx, u.ID, step.value)
+        if err != nil {
+            t.Fatalf("UpdateTOTPLastStep(%d): %v", step.value, err)
+        }
+        if ok != step.want {
+            t.Errorf("UpdateTOTPLastStep(%d) = %t, want %t", step.value, ok, step.want)
+        }
+    }
+
+    if err := repo.DisableTOTP(ctx, u.ID); err != nil {
+        t.Fatalf("DisableTOTP: %v", err)
+    }
+    stored = getUser(t, repo, u.ID)
+    if stored.TOTPSecret != "" || stored.TOTPEnabledAt != nil || stored.TOTPLastStep != 0 {
+        t.Errorf("after DisableTOTP: %+v", stored)
+    }
+
+    expectError(t, "SetTOTPSecret of a missing user", repo.SetTOTPSecret(ctx, u.ID+1000, "x"), repository.ErrUserNotFound)
+    expectError(t, "DisableTOTP of a missing user", repo.DisableTOTP(ctx, u.ID+1000), repository.ErrUserNotFound)
+}
+
+// testChangesBumpVersion checks that every change to the updated_at or the
+// MFA state of a user also changes the version its ETag is built from.
+func testChangesBumpVersion(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    u := createUser(t, repo, "Alice", "alice@example.com")
+
+    changes := []struct {
+        name   string
+        change func() error
+    }{
+        {"UpdateRole", func() error { return repo.UpdateRole(ctx, u.ID, models.RoleAdmin) }},
+        {"UpdatePassword", func() error { return repo.UpdatePassword(ctx, u.ID, "newsecret456") }},
+        {"MarkEmailVerified", func() error { return repo.MarkEmailVerified(ctx, u.ID) }},
+        {"SetTOTPSecret", func() error { return repo.SetTOTPSecret(ctx, u.ID, "JBSWY3DPEHPK3PXP") }},
+        {"EnableTOTP", func() error { return repo.EnableTOTP(ctx, u.ID) }},
+        {"SetTOTPSecret while enabled", func() error { return repo.SetTOTPSecret(ctx, u.ID, "KRSXG5CTMVRXEZLU") }},
+        {"DisableTOTP", func() error { return repo.DisableTOTP(ctx, u.ID) }},
+    }
+
+    before := getUser(t, repo, u.ID)
+    for _, c := range changes {
+        if err := c.change(); err != nil {
+            t.Fatalf("%s: %v", c.name, err)
+        }
+
+        after := getUser(t, repo, u.ID)
+        if after.Version != before.Version+1 {
+            t.Errorf("%s: Version = %d, want %d", c.name, after.Version, before.Version+1)
+        }
+        if after.UpdatedAt.Before(before.UpdatedAt) {
+            t.Errorf("%s: UpdatedAt went back from %v to %v", c.name, before.UpdatedAt, after.UpdatedAt)
+        }
+        before = after
+    }
+}
+
+func testListFilters(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    alice := createUser(t, repo, "Alice", "alice@example.com")
+    bob := createUser(t, repo, "Bob", "bob@test.org")
+    carol := createUser(t, repo, "Carol_1", "carol@example.com")
+
+    if err := repo.UpdateRole(ctx, bob.ID, models.RoleAdmin); err != nil {
+        t.Fatalf("UpdateRole: %v", err)
+    }
+    if err := repo.RecordLogin(ctx, carol.ID); err != nil {
+        t.Fatalf("RecordLogin: %v", err)
+    }
+
+    future := time.Now().Add(time.Hour)
+    past := time.Now().Add(-time.Hour)
+
+    tests := []struct {
+        name   string
+        params models.UserListParams
+        want   []int64
+    }{
+        {"all", models.UserListParams{}, []int64{alice.ID, bob.ID, carol.ID}},
+        {"email", models.UserListParams{EmailContains: "EXAMPLE"}, []int64{alice.ID, carol.ID}},
+        {"name", models.UserListParams{NameContains: "o"}, []int64{bob.ID, carol.ID}},
+        {"literal underscore", models.UserListParams{NameContains: "l_"}, []int64{carol.ID}},
+        {"literal percent", models.UserListParams{NameContains: "%"}, nil},
+        {"role", models.UserListParams{Role: models.RoleAdmin}, []int64{bob.ID}},
+        {"created after", models.UserListParams{CreatedAfter: &past}, []int64{alice.ID, bob.ID, carol.ID}},
+        {"created before", models.UserListParams{CreatedBefore: &past}, nil},
+        {"updated before", models.UserListParams{UpdatedBefore: &future}, []int64{alice.ID, bob.ID, carol.ID}},
+        {"last login after", models.UserListParams{LastLoginAfter: &past}, []int64{carol.ID}},
+        {"last login before", models.UserListParams{LastLoginBefore: &past}, []int64{alice.ID, bob.ID}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            params := tt.params
+            params.Limit = 10
+            params.Sort = "id"
+
+            page, err := repo.List(ctx, &params)
+            if err != nil {
+                t.Fatalf("List: %v", err)
+            }
+            if got := userIDs(page.Users); !equalIDs(got, tt.want) {
+                t.Errorf("List = %v, want %v", got, tt.want)
+            }
+            if page.Total != int64(len(tt.want)) {
+                t.Errorf("Total = %d, want %d", page.Total, len(tt.want))
+            }
+            for _, u := range page.Users {
+                if u.Password != "" || u.TOTPSecret != "" {
+                    t.Errorf("List exposed credentials of user %d", u.ID)
+                }
+            }
+        })
+    }
+}
+
+func testListPagination(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+
+    names := []string{"Dave", "Alice", "Carol", "Bob", "Alice"}
+    ids := make([]int64, len(names))
+    for i, name := range names {
+        ids[i] = createUser(t, repo, name, fmt.Sprintf("user%d@example.com", i)).ID
+    }
+
+    byName := []int64{ids[1], ids[4], ids[3], ids[2], ids[0]}
+    byNameDesc := []int64{ids[0], ids[2], ids[3], ids[4], ids[1]}
+
+    tests := []struct {
+        name string
+        sort string
+        desc bool
+        want []int64
+    }{
+        {"id", "id", false, ids},
+        {"name", "name", false, byName},
+        {"name desc", "name", true, byNameDesc},
+        {"created_at", "created_at", false, ids},
+        {"last_login_at", "last_login_at", false, ids},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var got []int64
+            params := &models.UserListParams{Limit: 2, Sort: tt.sort, Desc: tt.desc}
+            for pages := 0; ; pages++ {
+                if pages > len(ids) {
+                    t.Fatal("cursor pagination does not terminate")
+                }
+
+                page, err := repo.List(ctx, params)
+                if err != nil {
+                    t.Fatalf("List: %v", err)
+                }
+                if page.Total != int64(len(ids)) {
+                    t.Errorf("Total = %d, want %d", page.Total, len(ids))
+                }
+                got = append(got, userIDs(page.Users)...)
+
+                if page.NextCursor == "" {
+                    break
+                }
+                params.Cursor = page.NextCursor
+            }
+
+            if !equalIDs(got, tt.want) {
+                t.Errorf("pages = %v, want %v", got, tt.want)
+            }
+        })
+    }
+
+    page, err := repo.List(ctx, &models.UserListParams{Limit: 2, Offset: 2, Sort: "name"})
+    if err != nil {
+        t.Fatalf("List with offset: %v", err)
+    }
+    if got := userIDs(page.Users); !equalIDs(got, byName[2:4]) {
+        t.Errorf("List with offset = %v, want %v", got, byName[2:4])
+    }
+
+    page, err = repo.List(ctx, &models.UserListParams{Limit: 2, Offset: 10, Sort: "id"})
+    if err != nil {
+        t.Fatalf("List past the end: %v", err)
+    }
+    if len(page.Users) != 0 || page.NextCursor != "" {
+        t.Errorf("List past the end = %v, cursor %q", userIDs(page.Users), page.NextCursor)
+    }
+}
+
+func testListErrors(t *testing.T, repo repository.UserRepository) {
+    ctx := context.Background()
+    createUser(t, repo, "Alice", "alice@example.com")
+    createUser(t, repo, "Bob", "bob@example.com")
+
+    _, err := repo.List(ctx, &models.UserListParams{Limit: 10, Sort: "password"})
+    expectError(t, "List with an unknown sort field", err, repository.ErrInvalidSort)
+
+    _, err = repo.List(ctx, &models.UserListParams{Limit: 10, Sort: "id", Cursor: "not a cursor"})
+    expectError(t, "List with a malformed cursor", err, repository.ErrInvalidCursor)
+
+    page, err := repo.List(ctx, &models.UserListParams{Limit: 1, Sort: "name"})
+    if err != nil {
+        t.Fatalf("List: %v", err)
+    }
+    _, err = repo.List(ctx, &models.UserListParams{Limit: 1, Sort: "email", Cursor: page.NextCursor})
+    expectError(t, "List with a cursor for another sort", err, repository.ErrInvalidCursor)
+}
+
+func userIDs(users []models.User) []int64 {
+    ids := make([]int64, 0, len(users))
+    for _, u := range users {
+        ids = append(ids, u.ID)
+    }
+    return ids
+}
+
+func equalIDs(a, b []int64) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
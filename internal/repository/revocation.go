@@ -0,0 +1,92 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+)
+
+// RevocationStore tracks revoked access tokens. Single tokens are revoked by
+// jti. All tokens of a user are revoked by bumping the user's session
+// generation: tokens carry the generation they were issued in and are
+// rejected once it is behind the stored one.
+type RevocationStore interface {
+    RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+    IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+    RevokeUserTokens(ctx context.Context, userID int64) error
+    UserTokenGeneration(ctx context.Context, userID int64) (int64, error)
+}
+
+type revocationStore struct {
+    db *pgxpool.Pool
+}
+
+func NewRevocationStore(db *pgxpool.Pool) RevocationStore {
+    return &revocationStore{db: db}
+}
+
+func (s *revocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+    logging.Debug(ctx, "revoking token", "jti", jti)
+
+    _, err := pgxConn(ctx, s.db).Exec(ctx,
+        "INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING",
+        jti, expiresAt)
+    if err != nil {
+        logging.Error(ctx, "error revoking token", "error", err)
+        return err
+    }
+
+    _, err = pgxConn(ctx, s.db).Exec(ctx, "DELETE FROM revoked_tokens WHERE expires_at < NOW()")
+    if err != nil {
+        logging.Error(ctx, "error purging expired revoked tokens", "error", err)
+    }
+
+    return err
+}
+
+func (s *revocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+    var revoked bool
+    err := pgxConn(ctx, s.db).QueryRow(ctx,
+        "SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)", jti).
+        Scan(&revoked)
+    if err != nil {
+        logging.Error(ctx, "error checking token revocation", "error", err)
+    }
+
+    return revoked, err
+}
+
+func (s *revocationStore) RevokeUserTokens(ctx context.Context, userID int64) error {
+    logging.Debug(ctx, "revoking all user tokens", "user_id", userID)
+
+    _, err := pgxConn(ctx, s.db).Exec(ctx,
+        `INSERT INTO user_token_revocations (user_id, generation) VALUES ($1, 1)
+         ON CONFLICT (user_id) DO UPDATE SET generation = user_token_revocations.generation + 1`,
+        userID)
+    if err != nil {
+        logging.Error(ctx, "error revoking user tokens", "error", err)
+    }
+
+    return err
+}
+
+func (s *revocationStore) UserTokenGeneration(ctx context.Context, userID int64) (int64, error) {
+    var generation int64
+    err := pgxConn(ctx, s.db).QueryRow(ctx,
+        "SELECT generation FROM user_token_revocations WHERE user_id = $1", userID).
+        Scan(&generation)
+
+    if errors.Is(err, pgx.ErrNoRows) {
+        return 0, nil
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error fetching user token generation", "error", err)
+    }
+
+    return generation, err
+}
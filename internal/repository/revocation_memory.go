@@ -0,0 +1,58 @@
+package repository
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+type memoryRevocationStore struct {
+    mu          sync.RWMutex
+    tokens      map[string]time.Time
+    generations map[int64]int64
+}
+
+func NewMemoryRevocationStore() RevocationStore {
+    return &memoryRevocationStore{
+        tokens:      make(map[string]time.Time),
+        generations: make(map[int64]int64),
+    }
+}
+
+func (s *memoryRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    now := time.Now()
+    for id, exp := range s.tokens {
+        if exp.Before(now) {
+            delete(s.tokens, id)
+        }
+    }
+
+    s.tokens[jti] = expiresAt
+    return nil
+}
+
+func (s *memoryRevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    _, revoked := s.tokens[jti]
+    return revoked, nil
+}
+
+func (s *memoryRevocationStore) RevokeUserTokens(ctx context.Context, userID int64) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.generations[userID]++
+    return nil
+}
+
+func (s *memoryRevocationStore) UserTokenGeneration(ctx context.Context, userID int64) (int64, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    return s.generations[userID], nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+)
+
+type sqliteRevocationStore struct {
+    db *sql.DB
+}
+
+func NewSQLiteRevocationStore(db *sql.DB) RevocationStore {
+    return &sqliteRevocationStore{db: db}
+}
+
+func (s *sqliteRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+    logging.Debug(ctx, "revoking token", "jti", jti)
+
+    _, err := sqliteConn(ctx, s.db).ExecContext(ctx,
+        "INSERT INTO revoked_tokens (jti, expires_at) VALUES (?, ?) ON CONFLICT (jti) DO NOTHING",
+        jti, expiresAt.UTC())
+    if err != nil {
+        logging.Error(ctx, "error revoking token", "error", err)
+        return err
+    }
+
+    _, err = sqliteConn(ctx, s.db).ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < ?", dbNow())
+    if err != nil {
+        logging.Error(ctx, "error purging expired revoked tokens", "error", err)
+    }
+
+    return err
+}
+
+func (s *sqliteRevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+    var revoked bool
+    err := sqliteConn(ctx, s.db).QueryRowContext(ctx,
+        "SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = ?)", jti).
+        Scan(&revoked)
+    if err != nil {
+        logging.Error(ctx, "error checking token revocation", "error", err)
+    }
+
+    return revoked, err
+}
+
+func (s *sqliteRevocationStore) RevokeUserTokens(ctx context.Context, userID int64) error {
+    logging.Debug(ctx, "revoking all user tokens", "user_id", userID)
+
+    _, err := sqliteConn(ctx, s.db).ExecContext(ctx,
+        `INSERT INTO user_token_revocations (user_id, generation) VALUES (?, 1)
+         ON CONFLICT (user_id) DO UPDATE SET generation = user_token_revocations.generation + 1`,
+        userID)
+    if err != nil {
+        logging.Error(ctx, "error revoking user tokens", "error", err)
+    }
+
+    return err
+}
+
+func (s *sqliteRevocationStore) UserTokenGeneration(ctx context.Context, userID int64) (int64, error) {
+    var generation int64
+    err := sqliteConn(ctx, s.db).QueryRowContext(ctx,
+        "SELECT generation FROM user_token_revocations WHERE user_id = ?", userID).
+        Scan(&generation)
+
+    if errors.Is(err, sql.ErrNoRows) {
+        return 0, nil
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error fetching user token generation", "error", err)
+    }
+
+    return generation, err
+}
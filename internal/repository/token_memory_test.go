@@ -0,0 +1,29 @@
+package repository_test
+
+import (
+    "testing"
+
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/repository/repositorytest"
+)
+
+func newMemoryStores(t *testing.T) repositorytest.Stores {
+    return repositorytest.Stores{
+        Users:         repository.NewMemoryUserRepository(),
+        RefreshTokens: repository.NewMemoryRefreshTokenRepository(),
+        UserTokens:    repository.NewMemoryUserTokenRepository(),
+        RecoveryCodes: repository.NewMemoryRecoveryCodeRepository(),
+    }
+}
+
+func TestMemoryRefreshTokenRepository(t *testing.T) {
+    repositorytest.TestRefreshTokenRepository(t, newMemoryStores)
+}
+
+func TestMemoryUserTokenRepository(t *testing.T) {
+    repositorytest.TestUserTokenRepository(t, newMemoryStores)
+}
+
+func TestMemoryRecoveryCodeRepository(t *testing.T) {
+    repositorytest.TestRecoveryCodeRepository(t, newMemoryStores)
+}
@@ -0,0 +1,32 @@
+package repository_test
+
+import (
+    "testing"
+
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/repository/repositorytest"
+)
+
+func newPostgresStores(url string) repositorytest.NewStores {
+    return func(t *testing.T) repositorytest.Stores {
+        pool := openPostgres(t, url)
+        return repositorytest.Stores{
+            Users:         repository.NewUserRepository(pool),
+            RefreshTokens: repository.NewRefreshTokenRepository(pool),
+            UserTokens:    repository.NewUserTokenRepository(pool),
+            RecoveryCodes: repository.NewRecoveryCodeRepository(pool),
+        }
+    }
+}
+
+func TestPostgresRefreshTokenRepository(t *testing.T) {
+    repositorytest.TestRefreshTokenRepository(t, newPostgresStores(postgresURL(t)))
+}
+
+func TestPostgresUserTokenRepository(t *testing.T) {
+    repositorytest.TestUserTokenRepository(t, newPostgresStores(postgresURL(t)))
+}
+
+func TestPostgresRecoveryCodeRepository(t *testing.T) {
+    repositorytest.TestRecoveryCodeRepository(t, newPostgresStores(postgresURL(t)))
+}
@@ -0,0 +1,30 @@
+package repository_test
+
+import (
+    "testing"
+
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/repository/repositorytest"
+)
+
+func newSQLiteStores(t *testing.T) repositorytest.Stores {
+    sqlDB := openSQLite(t)
+    return repositorytest.Stores{
+        Users:         repository.NewSQLiteUserRepository(sqlDB),
+        RefreshTokens: repository.NewSQLiteRefreshTokenRepository(sqlDB),
+        UserTokens:    repository.NewSQLiteUserTokenRepository(sqlDB),
+        RecoveryCodes: repository.NewSQLiteRecoveryCodeRepository(sqlDB),
+    }
+}
+
+func TestSQLiteRefreshTokenRepository(t *testing.T) {
+    repositorytest.TestRefreshTokenRepository(t, newSQLiteStores)
+}
+
+func TestSQLiteUserTokenRepository(t *testing.T) {
+    repositorytest.TestUserTokenRepository(t, newSQLiteStores)
+}
+
+func TestSQLiteRecoveryCodeRepository(t *testing.T) {
+    repositorytest.TestRecoveryCodeRepository(t, newSQLiteStores)
+}
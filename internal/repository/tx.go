@@ -0,0 +1,124 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "math/rand/v2"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
+    "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+)
+
+// SQLSTATEs after which PostgreSQL expects the whole transaction to be retried.
+const (
+    serializationFailure = "40001"
+    deadlockDetected     = "40P01"
+)
+
+const retryBaseDelay = 10 * time.Millisecond
+
+// TxManager runs fn in a database transaction. Repositories called with the
+// context passed to fn take part in that transaction; a nested WithinTx runs
+// in a savepoint, so its failure can be handled without aborting the outer
+// transaction. fn may be called more than once when the transaction is
+// retried, so it must not have side effects outside the database.
+type TxManager interface {
+    WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// pgxQuerier is the part of pgxpool.Pool and pgx.Tx the repositories use.
+type pgxQuerier interface {
+    Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+    Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+    QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type pgxTxKey struct{}
+
+// pgxConn returns the transaction WithinTx stored in ctx, or the pool when
+// ctx carries none.
+func pgxConn(ctx context.Context, pool *pgxpool.Pool) pgxQuerier {
+    if tx, ok := ctx.Value(pgxTxKey{}).(pgx.Tx); ok {
+        return tx
+    }
+    return pool
+}
+
+type pgxTxManager struct {
+    pool       *pgxpool.Pool
+    options    pgx.TxOptions
+    maxRetries int
+}
+
+// NewTxManager returns a TxManager that starts transactions with options and
+// retries them up to maxRetries times on serialization failures and deadlocks.
+func NewTxManager(pool *pgxpool.Pool, options pgx.TxOptions, maxRetries int) TxManager {
+    return &pgxTxManager{pool: pool, options: options, maxRetries: maxRetries}
+}
+
+func (m *pgxTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+    if tx, ok := ctx.Value(pgxTxKey{}).(pgx.Tx); ok {
+        // A savepoint cannot be retried on its own: after a serialization
+        // failure the whole transaction is aborted, so the outermost
+        // WithinTx retries it.
+        return m.run(ctx, tx.Begin, fn)
+    }
+
+    for attempt := 0; ; attempt++ {
+        err := m.run(ctx, func(ctx context.Context) (pgx.Tx, error) {
+            return m.pool.BeginTx(ctx, m.options)
+        }, fn)
+        if err == nil || attempt >= m.maxRetries || !isRetryableTxError(err) {
+            return err
+        }
+
+        logging.Warn(ctx, "retrying transaction", "error", err, "attempt", attempt+1, "max_retries", m.maxRetries)
+        if err := sleepContext(ctx, retryDelay(attempt)); err != nil {
+            return err
+        }
+    }
+}
+
+func (m *pgxTxManager) run(ctx context.Context, begin func(context.Context) (pgx.Tx, error), fn func(ctx context.Context) error) error {
+    tx, err := begin(ctx)
+    if err != nil {
+        return err
+    }
+    // Rollback after a successful Commit is a no-op.
+    defer tx.Rollback(context.WithoutCancel(ctx))
+
+    if err := fn(context.WithValue(ctx, pgxTxKey{}, tx)); err != nil {
+        return err
+    }
+    return tx.Commit(ctx)
+}
+
+func isRetryableTxError(err error) bool {
+    var pgErr *pgconn.PgError
+    if !errors.As(err, &pgErr) {
+        return false
+    }
+    return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+}
+
+// retryDelay grows exponentially with jitter so that transactions that
+// conflicted once do not collide again.
+func retryDelay(attempt int) time.Duration {
+    d := retryBaseDelay << attempt
+    return d/2 + rand.N(d/2+1)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-timer.C:
+        return nil
+    }
+}
@@ -0,0 +1,16 @@
+package repository
+
+import "context"
+
+type memoryTxManager struct{}
+
+// NewMemoryTxManager returns a TxManager for the in-memory repositories. It
+// just calls fn: each repository call is atomic on its own, but changes made
+// before a failure are not rolled back.
+func NewMemoryTxManager() TxManager {
+    return memoryTxManager{}
+}
+
+func (memoryTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+    return fn(ctx)
+}
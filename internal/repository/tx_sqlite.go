@@ -0,0 +1,78 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+)
+
+// sqliteQuerier is the part of sql.DB and sql.Tx the repositories use.
+type sqliteQuerier interface {
+    ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+    QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+    QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type sqliteTxKey struct{}
+
+type sqliteTx struct {
+    *sql.Tx
+    depth int
+}
+
+// sqliteConn returns the transaction WithinTx stored in ctx, or db when ctx
+// carries none.
+func sqliteConn(ctx context.Context, db *sql.DB) sqliteQuerier {
+    if tx, ok := ctx.Value(sqliteTxKey{}).(*sqliteTx); ok {
+        return tx
+    }
+    return db
+}
+
+type sqliteTxManager struct {
+    db *sql.DB
+}
+
+// NewSQLiteTxManager returns a TxManager for SQLite. The database allows a
+// single writer, so transactions are never retried.
+func NewSQLiteTxManager(db *sql.DB) TxManager {
+    return &sqliteTxManager{db: db}
+}
+
+func (m *sqliteTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+    if tx, ok := ctx.Value(sqliteTxKey{}).(*sqliteTx); ok {
+        return tx.savepoint(ctx, fn)
+    }
+
+    tx, err := m.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if err := fn(context.WithValue(ctx, sqliteTxKey{}, &sqliteTx{Tx: tx})); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (tx *sqliteTx) savepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+    tx.depth++
+    defer func() { tx.depth-- }()
+
+    name := fmt.Sprintf("sp_%d", tx.depth)
+    if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+        return err
+    }
+
+    if err := fn(ctx); err != nil {
+        // ROLLBACK TO keeps the savepoint open, so it is released either way.
+        if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO "+name+"; RELEASE "+name); rbErr != nil {
+            return fmt.Errorf("%w (rollback to savepoint: %v)", err, rbErr)
+        }
+        return err
+    }
+
+    _, err := tx.ExecContext(ctx, "RELEASE "+name)
+    return err
+}
@@ -2,27 +2,44 @@ package repository
 
 import (
     "context"
-    "database/sql"
     "errors"
-    "log"
+    "fmt"
+    "strings"
+    "time"
 
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
     "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
     "github.com/MorozkoArt/go-crud-api/internal/models"
     "github.com/MorozkoArt/go-crud-api/internal/utils"
 )
 
 var (
-    ErrUserNotFound = errors.New("user not found")
-    ErrUserExists   = errors.New("user already exists")
+    ErrUserNotFound    = apperrors.NotFound("user not found")
+    ErrUserExists      = apperrors.Conflict("user with this email already exists")
+    ErrVersionMismatch = apperrors.PreconditionFailed("user has been modified since it was read")
 )
 
 type UserRepository interface {
     Create(ctx context.Context, user *models.User) error
     GetByEmail(ctx context.Context, email string) (*models.User, error)
     GetByID(ctx context.Context, id int64) (*models.User, error)
-    GetAll(ctx context.Context) ([]models.User, error)
-    Update(ctx context.Context, user *models.User) error
-    Delete(ctx context.Context, id int64) error
+    List(ctx context.Context, params *models.UserListParams) (*models.UserPage, error)
+    Update(ctx context.Context, user *models.User, ifMatch []int64) error
+    Patch(ctx context.Context, id int64, patch *models.UserPatch, ifMatch []int64) (int64, error)
+    Delete(ctx context.Context, id int64, ifMatch []int64) error
+    RecordLogin(ctx context.Context, id int64) error
+    Restore(ctx context.Context, id int64) error
+    Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+    UpdateRole(ctx context.Context, id int64, role string) error
+    UpdatePassword(ctx context.Context, id int64, password string) error
+    MarkEmailVerified(ctx context.Context, id int64) error
+    SetTOTPSecret(ctx context.Context, id int64, secret string) error
+    EnableTOTP(ctx context.Context, id int64) error
+    DisableTOTP(ctx context.Context, id int64) error
+    UpdateTOTPLastStep(ctx context.Context, id int64, step int64) (bool, error)
 }
 
 type userRepository struct {
@@ -34,140 +51,479 @@ func NewUserRepository(db *pgxpool.Pool) UserRepository {
 }
 
 func (r *userRepository) Create(ctx context.Context, u *models.User) error {
-    log.Printf("Creating user with email: %s", u.Email)
-    
-    var exists bool
-    err := r.db.QueryRow(ctx, 
-        "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", u.Email).
-        Scan(&exists)
+    logging.Debug(ctx, "creating user", "email", u.Email)
+
+    hashedPassword, err := utils.HashPassword(u.Password)
     if err != nil {
-        log.Printf("Error checking user existence: %v", err)
+        logging.Error(ctx, "error hashing password", "error", err)
         return err
     }
-    if exists {
-        log.Printf("User with email %s already exists", u.Email)
-        return ErrUserExists
+
+    if u.Role == "" {
+        u.Role = models.RoleUser
     }
 
-    hashedPassword, err := utils.HashPassword(u.Password)
+    err = pgxConn(ctx, r.db).QueryRow(ctx, 
+        `INSERT INTO users (name, email, password, role, created_at, updated_at) VALUES ($1, $2, $3, $4, NOW(), NOW())
+         RETURNING id, created_at, updated_at, version`,
+        u.Name, u.Email, hashedPassword, u.Role).
+        Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt, &u.Version)
+    if isUniqueViolation(err) {
+        logging.Debug(ctx, "user already exists", "email", u.Email)
+        return ErrUserExists
+    }
     if err != nil {
-        log.Printf("Error hashing password: %v", err)
+        logging.Error(ctx, "error creating user", "error", err)
         return err
     }
 
-    _, err = r.db.Exec(ctx, 
-        "INSERT INTO users (name, email, password) VALUES ($1, $2, $3)",
-        u.Name, u.Email, hashedPassword)
-        
-    if err != nil {
-        log.Printf("Error creating user: %v", err)
-    } else {
-        log.Printf("User created successfully: %s", u.Email)
-    }
-    
-    return err
+    u.Password = hashedPassword
+    logging.Info(ctx, "user created", "email", u.Email)
+    return nil
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-    log.Printf("Fetching user by email: %s", email)
+    logging.Debug(ctx, "fetching user", "email", email)
     
     var u models.User
-    err := r.db.QueryRow(ctx,
-        "SELECT id, name, email, password FROM users WHERE email=$1", email).
-        Scan(&u.ID, &u.Name, &u.Email, &u.Password)
+    err := pgxConn(ctx, r.db).QueryRow(ctx,
+        `SELECT id, name, email, password, role, email_verified_at, COALESCE(totp_secret, ''), totp_enabled_at, totp_last_step, created_at, updated_at, last_login_at, deleted_at, version
+         FROM users WHERE LOWER(email)=LOWER($1) AND deleted_at IS NULL`, email).
+        Scan(&u.ID, &u.Name, &u.Email, &u.Password, &u.Role, &u.EmailVerifiedAt, &u.TOTPSecret, &u.TOTPEnabledAt, &u.TOTPLastStep, &u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt, &u.DeletedAt, &u.Version)
     
-    if err == sql.ErrNoRows {
-        log.Printf("User not found with email: %s", email)
+    if errors.Is(err, pgx.ErrNoRows) {
+        logging.Debug(ctx, "user not found", "email", email)
         return nil, ErrUserNotFound
     }
     
     if err != nil {
-        log.Printf("Error fetching user by email: %v", err)
+        logging.Error(ctx, "error fetching user", "error", err)
     }
     
     return &u, err
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
-    log.Printf("Fetching user by ID: %d", id)
+    logging.Debug(ctx, "fetching user", "user_id", id)
     
     var u models.User
-    err := r.db.QueryRow(ctx,
-        "SELECT id, name, email FROM users WHERE id=$1", id).
-        Scan(&u.ID, &u.Name, &u.Email)
+    err := pgxConn(ctx, r.db).QueryRow(ctx,
+        `SELECT id, name, email, password, role, email_verified_at, COALESCE(totp_secret, ''), totp_enabled_at, totp_last_step, created_at, updated_at, last_login_at, deleted_at, version
+         FROM users WHERE id=$1 AND deleted_at IS NULL`, id).
+        Scan(&u.ID, &u.Name, &u.Email, &u.Password, &u.Role, &u.EmailVerifiedAt, &u.TOTPSecret, &u.TOTPEnabledAt, &u.TOTPLastStep, &u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt, &u.DeletedAt, &u.Version)
     
-    if err == sql.ErrNoRows {
-        log.Printf("User not found with ID: %d", id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        logging.Debug(ctx, "user not found", "user_id", id)
         return nil, ErrUserNotFound
     }
     
     if err != nil {
-        log.Printf("Error fetching user by ID: %v", err)
+        logging.Error(ctx, "error fetching user", "error", err)
     }
     
     return &u, err
 }
 
-func (r *userRepository) GetAll(ctx context.Context) ([]models.User, error) {
-    log.Printf("Fetching all users")
+func (r *userRepository) List(ctx context.Context, p *models.UserListParams) (*models.UserPage, error) {
+    logging.Debug(ctx, "listing users", "sort", p.Sort, "desc", p.Desc, "limit", p.Limit, "offset", p.Offset)
     
-    rows, err := r.db.Query(ctx, "SELECT id, name, email FROM users ORDER BY id")
+    sortCol, ok := userSortColumns[p.Sort]
+    if !ok {
+        return nil, ErrInvalidSort
+    }
+
+    var conds []string
+    var args []interface{}
+    addCond := func(format string, value interface{}) {
+        args = append(args, value)
+        conds = append(conds, fmt.Sprintf(format, len(args)))
+    }
+
+    if !p.IncludeDeleted {
+        conds = append(conds, "deleted_at IS NULL")
+    }
+    if p.EmailContains != "" {
+        addCond(`email ILIKE '%%' || $%d || '%%'`, escapeLike(p.EmailContains))
+    }
+    if p.NameContains != "" {
+        addCond(`name ILIKE '%%' || $%d || '%%'`, escapeLike(p.NameContains))
+    }
+    if p.Role != "" {
+        addCond("role = $%d", p.Role)
+    }
+    if p.CreatedAfter != nil {
+        addCond("created_at > $%d", *p.CreatedAfter)
+    }
+    if p.CreatedBefore != nil {
+        addCond("created_at < $%d", *p.CreatedBefore)
+    }
+    if p.UpdatedAfter != nil {
+        addCond("updated_at > $%d", *p.UpdatedAfter)
+    }
+    if p.UpdatedBefore != nil {
+        addCond("updated_at < $%d", *p.UpdatedBefore)
+    }
+    if p.LastLoginAfter != nil {
+        addCond("last_login_at > $%d", *p.LastLoginAfter)
+    }
+    if p.LastLoginBefore != nil {
+        addCond("(last_login_at < $%d OR last_login_at IS NULL)", *p.LastLoginBefore)
+    }
+
+    var total int64
+    err := pgxConn(ctx, r.db).QueryRow(ctx, "SELECT COUNT(*) FROM users"+whereClause(conds), args...).Scan(&total)
+    if err != nil {
+        logging.Error(ctx, "error counting users", "error", err)
+        return nil, err
+    }
+
+    cmp, order := ">", "ASC"
+    if p.Desc {
+        cmp, order = "<", "DESC"
+    }
+
+    if p.Cursor != "" {
+        c, err := decodeUserCursor(p)
+        if err != nil {
+            return nil, err
+        }
+
+        if sortCol.column == "id" {
+            addCond("id "+cmp+" $%d", c.ID)
+        } else {
+            args = append(args, c.Value, c.ID)
+            conds = append(conds, fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)",
+                sortCol.column, cmp, len(args)-1, sortCol.cast, len(args)))
+        }
+    }
+
+    orderBy := sortCol.column + " " + order
+    if sortCol.column != "id" {
+        orderBy += ", id " + order
+    }
+
+    query := "SELECT id, name, email, role, email_verified_at, totp_enabled_at, created_at, updated_at, last_login_at, deleted_at, version FROM users" +
+        whereClause(conds) + " ORDER BY " + orderBy
+
+    args = append(args, p.Limit+1)
+    query += fmt.Sprintf(" LIMIT $%d", len(args))
+    if p.Cursor == "" && p.Offset > 0 {
+        args = append(args, p.Offset)
+        query += fmt.Sprintf(" OFFSET $%d", len(args))
+    }
+
+    rows, err := pgxConn(ctx, r.db).Query(ctx, query, args...)
     if err != nil {
-        log.Printf("Error fetching all users: %v", err)
+        logging.Error(ctx, "error listing users", "error", err)
         return nil, err
     }
     defer rows.Close()
 
-    var users []models.User
+    users := make([]models.User, 0, p.Limit)
     for rows.Next() {
         var u models.User
-        if err := rows.Scan(&u.ID, &u.Name, &u.Email); err != nil {
-            log.Printf("Error scanning user row: %v", err)
+        if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.EmailVerifiedAt, &u.TOTPEnabledAt, &u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt, &u.DeletedAt, &u.Version); err != nil {
+            logging.Error(ctx, "error scanning user row", "error", err)
             return nil, err
         }
         users = append(users, u)
     }
+    if err := rows.Err(); err != nil {
+        logging.Error(ctx, "error iterating user rows", "error", err)
+        return nil, err
+    }
+
+    page := &models.UserPage{Total: total}
+    if len(users) > p.Limit {
+        users = users[:p.Limit]
+        page.NextCursor = encodeUserCursor(p, &users[len(users)-1])
+    }
+    page.Users = users
 
-    log.Printf("Fetched %d users", len(users))
-    return users, nil
+    logging.Debug(ctx, "users listed", "count", len(users), "total", total)
+    return page, nil
 }
 
-func (r *userRepository) Update(ctx context.Context, u *models.User) error {
-    log.Printf("Updating user ID: %d", u.ID)
+func (r *userRepository) Update(ctx context.Context, u *models.User, ifMatch []int64) error {
+    logging.Debug(ctx, "updating user", "user_id", u.ID)
     
-    result, err := r.db.Exec(ctx, 
-        "UPDATE users SET name=$1, email=$2 WHERE id=$3",
-        u.Name, u.Email, u.ID)
+    err := pgxConn(ctx, r.db).QueryRow(ctx, 
+        `UPDATE users SET name=$1, email=$2,
+             email_verified_at = CASE WHEN lower(email) = lower($2) THEN email_verified_at END,
+             version=version+1, updated_at=NOW()
+         WHERE id=$3 AND deleted_at IS NULL AND ($4::bigint[] IS NULL OR version = ANY($4))
+         RETURNING version`,
+        u.Name, u.Email, u.ID, ifMatch).Scan(&u.Version)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return r.writeMissError(ctx, u.ID)
+    }
+    if isUniqueViolation(err) {
+        return ErrUserExists
+    }
     if err != nil {
-        log.Printf("Error updating user: %v", err)
+        logging.Error(ctx, "error updating user", "error", err)
+        return err
+    }
+    
+    logging.Info(ctx, "user updated", "user_id", u.ID)
+    return nil
+}
+
+func (r *userRepository) Patch(ctx context.Context, id int64, patch *models.UserPatch, ifMatch []int64) (int64, error) {
+    logging.Debug(ctx, "patching user", "user_id", id)
+
+    sets := []string{"version=version+1", "updated_at=NOW()"}
+    var args []interface{}
+    if patch.Name != nil {
+        args = append(args, *patch.Name)
+        sets = append(sets, fmt.Sprintf("name=$%d", len(args)))
+    }
+    if patch.Email != nil {
+        args = append(args, *patch.Email)
+        sets = append(sets, fmt.Sprintf("email=$%d", len(args)),
+            fmt.Sprintf("email_verified_at = CASE WHEN lower(email) = lower($%d) THEN email_verified_at END", len(args)))
+    }
+
+    args = append(args, id, ifMatch)
+    query := fmt.Sprintf(
+        "UPDATE users SET %s WHERE id=$%d AND deleted_at IS NULL AND ($%d::bigint[] IS NULL OR version = ANY($%d)) RETURNING version",
+        strings.Join(sets, ", "), len(args)-1, len(args), len(args))
+
+    var version int64
+    err := pgxConn(ctx, r.db).QueryRow(ctx, query, args...).Scan(&version)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return 0, r.writeMissError(ctx, id)
+    }
+    if isUniqueViolation(err) {
+        return 0, ErrUserExists
+    }
+    if err != nil {
+        logging.Error(ctx, "error patching user", "error", err)
+        return 0, err
+    }
+
+    logging.Info(ctx, "user patched", "user_id", id)
+    return version, nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, id int64, ifMatch []int64) error {
+    logging.Debug(ctx, "deleting user", "user_id", id)
+    
+    result, err := pgxConn(ctx, r.db).Exec(ctx,
+        `UPDATE users SET deleted_at=NOW(), version=version+1, updated_at=NOW()
+         WHERE id=$1 AND deleted_at IS NULL AND ($2::bigint[] IS NULL OR version = ANY($2))`,
+        id, ifMatch)
+    if err != nil {
+        logging.Error(ctx, "error deleting user", "error", err)
         return err
     }
     
     rowsAffected := result.RowsAffected()
     if rowsAffected == 0 {
-        log.Printf("User not found for update: %d", u.ID)
+        return r.writeMissError(ctx, id)
+    }
+    
+    logging.Info(ctx, "user deleted", "user_id", id)
+    return nil
+}
+
+// writeMissError explains why a conditional write touched no rows: either the
+// user does not exist or its version did not match the If-Match precondition.
+func (r *userRepository) writeMissError(ctx context.Context, id int64) error {
+    var exists bool
+    err := pgxConn(ctx, r.db).QueryRow(ctx,
+        "SELECT EXISTS(SELECT 1 FROM users WHERE id=$1 AND deleted_at IS NULL)", id).
+        Scan(&exists)
+    if err != nil {
+        logging.Error(ctx, "error checking user existence", "error", err)
+        return err
+    }
+
+    if exists {
+        logging.Debug(ctx, "user version mismatch", "user_id", id)
+        return ErrVersionMismatch
+    }
+
+    logging.Debug(ctx, "user not found for write", "user_id", id)
+    return ErrUserNotFound
+}
+
+func (r *userRepository) Restore(ctx context.Context, id int64) error {
+    logging.Debug(ctx, "restoring user", "user_id", id)
+
+    result, err := pgxConn(ctx, r.db).Exec(ctx, "UPDATE users SET deleted_at=NULL, version=version+1, updated_at=NOW() WHERE id=$1 AND deleted_at IS NOT NULL", id)
+    if err != nil {
+        logging.Error(ctx, "error restoring user", "error", err)
+        return err
+    }
+
+    if result.RowsAffected() == 0 {
+        logging.Debug(ctx, "deleted user not found for restore", "user_id", id)
         return ErrUserNotFound
     }
+
+    logging.Info(ctx, "user restored", "user_id", id)
+    return nil
+}
+
+func (r *userRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+    result, err := pgxConn(ctx, r.db).Exec(ctx, "DELETE FROM users WHERE deleted_at < $1", deletedBefore)
+    if err != nil {
+        logging.Error(ctx, "error purging deleted users", "error", err)
+        return 0, err
+    }
+
+    return result.RowsAffected(), nil
+}
+
+// RecordLogin leaves the version alone: a login is not an edit, and bumping
+// it would fail the If-Match of anyone editing the user at the time.
+func (r *userRepository) RecordLogin(ctx context.Context, id int64) error {
+    _, err := pgxConn(ctx, r.db).Exec(ctx,
+        "UPDATE users SET last_login_at=NOW() WHERE id=$1 AND deleted_at IS NULL", id)
+    if err != nil {
+        logging.Error(ctx, "error recording login", "user_id", id, "error", err)
+    }
+    return err
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id int64, role string) error {
+    logging.Debug(ctx, "updating user role", "user_id", id)
     
-    log.Printf("User updated successfully: %d", u.ID)
+    result, err := pgxConn(ctx, r.db).Exec(ctx, "UPDATE users SET role=$1, version=version+1, updated_at=NOW() WHERE id=$2 AND deleted_at IS NULL", role, id)
+    if err != nil {
+        logging.Error(ctx, "error updating user role", "error", err)
+        return err
+    }
+    
+    if result.RowsAffected() == 0 {
+        logging.Debug(ctx, "user not found for role update", "user_id", id)
+        return ErrUserNotFound
+    }
+    
+    logging.Info(ctx, "user role updated", "user_id", id)
     return nil
 }
 
-func (r *userRepository) Delete(ctx context.Context, id int64) error {
-    log.Printf("Deleting user ID: %d", id)
+func (r *userRepository) UpdatePassword(ctx context.Context, id int64, password string) error {
+    logging.Debug(ctx, "updating user password", "user_id", id)
     
-    result, err := r.db.Exec(ctx, "DELETE FROM users WHERE id=$1", id)
+    hashedPassword, err := utils.HashPassword(password)
     if err != nil {
-        log.Printf("Error deleting user: %v", err)
+        logging.Error(ctx, "error hashing password", "error", err)
+        return err
+    }
+
+    result, err := pgxConn(ctx, r.db).Exec(ctx, "UPDATE users SET password=$1, version=version+1, updated_at=NOW() WHERE id=$2 AND deleted_at IS NULL", hashedPassword, id)
+    if err != nil {
+        logging.Error(ctx, "error updating user password", "error", err)
         return err
     }
     
-    rowsAffected := result.RowsAffected()
-    if rowsAffected == 0 {
-        log.Printf("User not found for deletion: %d", id)
+    if result.RowsAffected() == 0 {
+        logging.Debug(ctx, "user not found for password update", "user_id", id)
+        return ErrUserNotFound
+    }
+    
+    logging.Info(ctx, "user password updated", "user_id", id)
+    return nil
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+    logging.Debug(ctx, "marking email verified", "user_id", id)
+    
+    result, err := pgxConn(ctx, r.db).Exec(ctx,
+        "UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), version=version+1, updated_at=NOW() WHERE id=$1 AND deleted_at IS NULL", id)
+    if err != nil {
+        logging.Error(ctx, "error marking email verified", "error", err)
+        return err
+    }
+    
+    if result.RowsAffected() == 0 {
+        logging.Debug(ctx, "user not found for email verification", "user_id", id)
+        return ErrUserNotFound
+    }
+    
+    return nil
+}
+
+func (r *userRepository) SetTOTPSecret(ctx context.Context, id int64, secret string) error {
+    logging.Debug(ctx, "setting pending TOTP secret", "user_id", id)
+    
+    result, err := pgxConn(ctx, r.db).Exec(ctx,
+        "UPDATE users SET totp_secret=$1, totp_enabled_at=NULL, totp_last_step=0, version=version+1, updated_at=NOW() WHERE id=$2 AND deleted_at IS NULL", secret, id)
+    if err != nil {
+        logging.Error(ctx, "error setting TOTP secret", "error", err)
+        return err
+    }
+    
+    if result.RowsAffected() == 0 {
         return ErrUserNotFound
     }
     
-    log.Printf("User deleted successfully: %d", id)
     return nil
+}
+
+func (r *userRepository) EnableTOTP(ctx context.Context, id int64) error {
+    logging.Debug(ctx, "enabling TOTP", "user_id", id)
+    
+    result, err := pgxConn(ctx, r.db).Exec(ctx,
+        "UPDATE users SET totp_enabled_at=NOW(), version=version+1, updated_at=NOW() WHERE id=$1 AND deleted_at IS NULL AND totp_secret IS NOT NULL", id)
+    if err != nil {
+        logging.Error(ctx, "error enabling TOTP", "error", err)
+        return err
+    }
+    
+    if result.RowsAffected() == 0 {
+        return ErrUserNotFound
+    }
+    
+    return nil
+}
+
+func (r *userRepository) DisableTOTP(ctx context.Context, id int64) error {
+    logging.Debug(ctx, "disabling TOTP", "user_id", id)
+    
+    result, err := pgxConn(ctx, r.db).Exec(ctx,
+        "UPDATE users SET totp_secret=NULL, totp_enabled_at=NULL, totp_last_step=0, version=version+1, updated_at=NOW() WHERE id=$1 AND deleted_at IS NULL", id)
+    if err != nil {
+        logging.Error(ctx, "error disabling TOTP", "error", err)
+        return err
+    }
+    
+    if result.RowsAffected() == 0 {
+        return ErrUserNotFound
+    }
+    
+    return nil
+}
+
+func (r *userRepository) UpdateTOTPLastStep(ctx context.Context, id int64, step int64) (bool, error) {
+    result, err := pgxConn(ctx, r.db).Exec(ctx,
+        "UPDATE users SET totp_last_step=$1 WHERE id=$2 AND deleted_at IS NULL AND totp_last_step < $1", step, id)
+    if err != nil {
+        logging.Error(ctx, "error updating TOTP step", "error", err)
+        return false, err
+    }
+    
+    return result.RowsAffected() == 1, nil
+}
+
+// uniqueViolation is the SQLSTATE PostgreSQL reports for unique constraint violations.
+const uniqueViolation = "23505"
+
+func isUniqueViolation(err error) bool {
+    var pgErr *pgconn.PgError
+    return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+func whereClause(conds []string) string {
+    if len(conds) == 0 {
+        return ""
+    }
+    return " WHERE " + strings.Join(conds, " AND ")
+}
+
+func escapeLike(s string) string {
+    return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
\ No newline at end of file
@@ -0,0 +1,516 @@
+package repository
+
+import (
+    "context"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+type memoryUserRepository struct {
+    mu     sync.RWMutex
+    nextID int64
+    users  map[int64]*models.User
+    emails map[string]int64
+}
+
+// NewMemoryUserRepository returns a UserRepository that keeps users in
+// memory with the same semantics as the PostgreSQL implementation: emails
+// are unique case-insensitively (including soft-deleted users), passwords
+// are hashed and every representation change except a login bumps the
+// version.
+func NewMemoryUserRepository() UserRepository {
+    return &memoryUserRepository{
+        users:  make(map[int64]*models.User),
+        emails: make(map[string]int64),
+    }
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, u *models.User) error {
+    hashedPassword, err := utils.HashPassword(u.Password)
+    if err != nil {
+        return err
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    key := strings.ToLower(u.Email)
+    if _, exists := r.emails[key]; exists {
+        return ErrUserExists
+    }
+
+    if u.Role == "" {
+        u.Role = models.RoleUser
+    }
+
+    r.nextID++
+    created := dbNow()
+
+    u.ID = r.nextID
+    u.Password = hashedPassword
+    u.CreatedAt = created
+    u.UpdatedAt = created
+    u.Version = 1
+
+    stored := *u
+    stored.EmailVerifiedAt = nil
+    stored.TOTPSecret = ""
+    stored.TOTPEnabledAt = nil
+    stored.TOTPLastStep = 0
+    stored.LastLoginAt = nil
+    stored.DeletedAt = nil
+
+    r.users[u.ID] = &stored
+    r.emails[key] = u.ID
+    return nil
+}
+
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    id, ok := r.emails[strings.ToLower(email)]
+    if !ok {
+        return nil, ErrUserNotFound
+    }
+    return r.getLocked(id)
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    return r.getLocked(id)
+}
+
+func (r *memoryUserRepository) getLocked(id int64) (*models.User, error) {
+    u, ok := r.active(id)
+    if !ok {
+        return nil, ErrUserNotFound
+    }
+
+    copied := *u
+    return &copied, nil
+}
+
+func (r *memoryUserRepository) active(id int64) (*models.User, bool) {
+    u, ok := r.users[id]
+    if !ok || u.DeletedAt != nil {
+        return nil, false
+    }
+    return u, true
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, p *models.UserListParams) (*models.UserPage, error) {
+    if _, ok := userSortColumns[p.Sort]; !ok {
+        return nil, ErrInvalidSort
+    }
+
+    var after *models.User
+    if p.Cursor != "" {
+        c, err := decodeUserCursor(p)
+        if err != nil {
+            return nil, err
+        }
+        if after, err = cursorUser(c); err != nil {
+            return nil, err
+        }
+    }
+
+    r.mu.RLock()
+    matched := make([]models.User, 0, len(r.users))
+    for _, u := range r.users {
+        if matchesUserFilter(u, p) {
+            listed := *u
+            listed.Password = ""
+            listed.TOTPSecret = ""
+            listed.TOTPLastStep = 0
+            matched = append(matched, listed)
+        }
+    }
+    r.mu.RUnlock()
+
+    sort.Slice(matched, func(i, j int) bool {
+        return orderedBefore(&matched[i], &matched[j], p)
+    })
+
+    page := &models.UserPage{Total: int64(len(matched))}
+
+    users := matched
+    if after != nil {
+        start := sort.Search(len(users), func(i int) bool {
+            return orderedBefore(after, &users[i], p)
+        })
+        users = users[start:]
+    } else if p.Offset > 0 {
+        users = users[min(p.Offset, len(users)):]
+    }
+
+    if len(users) > p.Limit {
+        users = users[:p.Limit]
+        page.NextCursor = encodeUserCursor(p, &users[len(users)-1])
+    }
+    page.Users = users
+
+    return page, nil
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, u *models.User, ifMatch []int64) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    stored, err := r.writableLocked(u.ID, ifMatch)
+    if err != nil {
+        return err
+    }
+    if err := r.renameLocked(stored, u.Email); err != nil {
+        return err
+    }
+
+    stored.Name = u.Name
+    r.touch(stored)
+
+    u.Version = stored.Version
+    return nil
+}
+
+func (r *memoryUserRepository) Patch(ctx context.Context, id int64, patch *models.UserPatch, ifMatch []int64) (int64, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    stored, err := r.writableLocked(id, ifMatch)
+    if err != nil {
+        return 0, err
+    }
+    if patch.Email != nil {
+        if err := r.renameLocked(stored, *patch.Email); err != nil {
+            return 0, err
+        }
+    }
+    if patch.Name != nil {
+        stored.Name = *patch.Name
+    }
+    r.touch(stored)
+
+    return stored.Version, nil
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id int64, ifMatch []int64) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    stored, err := r.writableLocked(id, ifMatch)
+    if err != nil {
+        return err
+    }
+
+    deletedAt := dbNow()
+    stored.DeletedAt = &deletedAt
+    r.touch(stored)
+    return nil
+}
+
+// writableLocked resolves a conditional write the way writeMissError does
+// for PostgreSQL: a missing user wins over a version mismatch.
+func (r *memoryUserRepository) writableLocked(id int64, ifMatch []int64) (*models.User, error) {
+    u, ok := r.active(id)
+    if !ok {
+        return nil, ErrUserNotFound
+    }
+    if ifMatch != nil && !containsVersion(ifMatch, u.Version) {
+        return nil, ErrVersionMismatch
+    }
+    return u, nil
+}
+
+func (r *memoryUserRepository) renameLocked(u *models.User, email string) error {
+    key := strings.ToLower(email)
+    if owner, exists := r.emails[key]; exists && owner != u.ID {
+        return ErrUserExists
+    }
+
+    delete(r.emails, strings.ToLower(u.Email))
+    r.emails[key] = u.ID
+    if !strings.EqualFold(u.Email, email) {
+        u.EmailVerifiedAt = nil
+    }
+    u.Email = email
+    return nil
+}
+
+func (r *memoryUserRepository) touch(u *models.User) {
+    u.Version++
+    u.UpdatedAt = dbNow()
+}
+
+func (r *memoryUserRepository) Restore(ctx context.Context, id int64) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    u, ok := r.users[id]
+    if !ok || u.DeletedAt == nil {
+        return ErrUserNotFound
+    }
+
+    u.DeletedAt = nil
+    r.touch(u)
+    return nil
+}
+
+func (r *memoryUserRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    var purged int64
+    for id, u := range r.users {
+        if u.DeletedAt != nil && u.DeletedAt.Before(deletedBefore) {
+            delete(r.emails, strings.ToLower(u.Email))
+            delete(r.users, id)
+            purged++
+        }
+    }
+    return purged, nil
+}
+
+func (r *memoryUserRepository) RecordLogin(ctx context.Context, id int64) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if u, ok := r.active(id); ok {
+        loginAt := dbNow()
+        u.LastLoginAt = &loginAt
+    }
+    return nil
+}
+
+func (r *memoryUserRepository) UpdateRole(ctx context.Context, id int64, role string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    u, ok := r.active(id)
+    if !ok {
+        return ErrUserNotFound
+    }
+
+    u.Role = role
+    r.touch(u)
+    return nil
+}
+
+func (r *memoryUserRepository) UpdatePassword(ctx context.Context, id int64, password string) error {
+    hashedPassword, err := utils.HashPassword(password)
+    if err != nil {
+        return err
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    u, ok := r.active(id)
+    if !ok {
+        return ErrUserNotFound
+    }
+
+    u.Password = hashedPassword
+    r.touch(u)
+    return nil
+}
+
+func (r *memoryUserRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    u, ok := r.active(id)
+    if !ok {
+        return ErrUserNotFound
+    }
+
+    if u.EmailVerifiedAt == nil {
+        verifiedAt := dbNow()
+        u.EmailVerifiedAt = &verifiedAt
+    }
+    r.touch(u)
+    return nil
+}
+
+func (r *memoryUserRepository) SetTOTPSecret(ctx context.Context, id int64, secret string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    u, ok := r.active(id)
+    if !ok {
+        return ErrUserNotFound
+    }
+
+    u.TOTPSecret = secret
+    u.TOTPEnabledAt = nil
+    u.TOTPLastStep = 0
+    r.touch(u)
+    return nil
+}
+
+func (r *memoryUserRepository) EnableTOTP(ctx context.Context, id int64) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    u, ok := r.active(id)
+    if !ok || u.TOTPSecret == "" {
+        return ErrUserNotFound
+    }
+
+    enabledAt := dbNow()
+    u.TOTPEnabledAt = &enabledAt
+    r.touch(u)
+    return nil
+}
+
+func (r *memoryUserRepository) DisableTOTP(ctx context.Context, id int64) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    u, ok := r.active(id)
+    if !ok {
+        return ErrUserNotFound
+    }
+
+    u.TOTPSecret = ""
+    u.TOTPEnabledAt = nil
+    u.TOTPLastStep = 0
+    r.touch(u)
+    return nil
+}
+
+func (r *memoryUserRepository) UpdateTOTPLastStep(ctx context.Context, id int64, step int64) (bool, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    u, ok := r.active(id)
+    if !ok || u.TOTPLastStep >= step {
+        return false, nil
+    }
+
+    u.TOTPLastStep = step
+    return true, nil
+}
+
+func matchesUserFilter(u *models.User, p *models.UserListParams) bool {
+    switch {
+    case !p.IncludeDeleted && u.DeletedAt != nil:
+        return false
+    case p.EmailContains != "" && !containsFold(u.Email, p.EmailContains):
+        return false
+    case p.NameContains != "" && !containsFold(u.Name, p.NameContains):
+        return false
+    case p.Role != "" && u.Role != p.Role:
+        return false
+    case p.CreatedAfter != nil && !u.CreatedAt.After(*p.CreatedAfter):
+        return false
+    case p.CreatedBefore != nil && !u.CreatedAt.Before(*p.CreatedBefore):
+        return false
+    case p.UpdatedAfter != nil && !u.UpdatedAt.After(*p.UpdatedAfter):
+        return false
+    case p.UpdatedBefore != nil && !u.UpdatedAt.Before(*p.UpdatedBefore):
+        return false
+    case p.LastLoginAfter != nil && (u.LastLoginAt == nil || !u.LastLoginAt.After(*p.LastLoginAfter)):
+        return false
+    case p.LastLoginBefore != nil && u.LastLoginAt != nil && !u.LastLoginAt.Before(*p.LastLoginBefore):
+        return false
+    }
+    return true
+}
+
+func containsFold(s, substr string) bool {
+    return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// orderedBefore reports whether a comes before b in the listing order,
+// i.e. by the sort field and then by id, both in the requested direction.
+func orderedBefore(a, b *models.User, p *models.UserListParams) bool {
+    c := compareBySort(a, b, p.Sort)
+    if c == 0 {
+        c = compareInt64(a.ID, b.ID)
+    }
+    if p.Desc {
+        return c > 0
+    }
+    return c < 0
+}
+
+func compareBySort(a, b *models.User, sort string) int {
+    switch sort {
+    case "name":
+        return strings.Compare(a.Name, b.Name)
+    case "email":
+        return strings.Compare(a.Email, b.Email)
+    case "created_at":
+        return a.CreatedAt.Compare(b.CreatedAt)
+    case "updated_at":
+        return a.UpdatedAt.Compare(b.UpdatedAt)
+    case "last_login_at":
+        return lastLoginOrEpoch(a).Compare(lastLoginOrEpoch(b))
+    }
+    return 0
+}
+
+func lastLoginOrEpoch(u *models.User) time.Time {
+    if u.LastLoginAt == nil {
+        return time.Unix(0, 0)
+    }
+    return *u.LastLoginAt
+}
+
+func compareInt64(a, b int64) int {
+    switch {
+    case a < b:
+        return -1
+    case a > b:
+        return 1
+    }
+    return 0
+}
+
+// cursorUser turns a decoded cursor back into a user carrying just the
+// fields orderedBefore looks at.
+func cursorUser(c *userCursor) (*models.User, error) {
+    u := &models.User{ID: c.ID}
+
+    switch c.Sort {
+    case "id":
+        return u, nil
+    case "name":
+        u.Name = c.Value
+        return u, nil
+    case "email":
+        u.Email = c.Value
+        return u, nil
+    }
+
+    t, err := time.Parse(time.RFC3339Nano, c.Value)
+    if err != nil {
+        return nil, ErrInvalidCursor
+    }
+
+    switch c.Sort {
+    case "created_at":
+        u.CreatedAt = t
+    case "updated_at":
+        u.UpdatedAt = t
+    case "last_login_at":
+        u.LastLoginAt = &t
+    }
+    return u, nil
+}
+
+func containsVersion(versions []int64, version int64) bool {
+    for _, v := range versions {
+        if v == version {
+            return true
+        }
+    }
+    return false
+}
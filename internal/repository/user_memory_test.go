@@ -0,0 +1,14 @@
+package repository_test
+
+import (
+    "testing"
+
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/repository/repositorytest"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+    repositorytest.TestUserRepository(t, func(t *testing.T) repository.UserRepository {
+        return repository.NewMemoryUserRepository()
+    })
+}
@@ -0,0 +1,65 @@
+package repository_test
+
+import (
+    "context"
+    "os"
+    "testing"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/MorozkoArt/go-crud-api/internal/db"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/repository/repositorytest"
+)
+
+// postgresURL returns TEST_DATABASE_URL and skips the test when it is not
+// set. Every subtest empties all tables, so the URL must point at a database
+// that holds nothing of value.
+func postgresURL(t *testing.T) string {
+    t.Helper()
+
+    url := os.Getenv("TEST_DATABASE_URL")
+    if url == "" {
+        t.Skip("TEST_DATABASE_URL is not set")
+    }
+    return url
+}
+
+// openPostgres connects to url, applies the migrations and empties every
+// table.
+func openPostgres(t *testing.T, url string) *pgxpool.Pool {
+    t.Helper()
+
+    ctx := context.Background()
+    pool, err := pgxpool.New(ctx, url)
+    if err != nil {
+        t.Fatalf("connect to PostgreSQL: %v", err)
+    }
+    t.Cleanup(pool.Close)
+
+    migrator, err := db.NewMigrator(pool)
+    if err != nil {
+        t.Fatalf("create migrator: %v", err)
+    }
+    defer migrator.Close()
+
+    if err := migrator.Up(ctx); err != nil {
+        t.Fatalf("migrate: %v", err)
+    }
+
+    _, err = pool.Exec(ctx, `
+        TRUNCATE users, refresh_tokens, user_tokens, recovery_codes,
+            revoked_tokens, user_token_revocations, login_attempts
+        RESTART IDENTITY CASCADE`)
+    if err != nil {
+        t.Fatalf("truncate tables: %v", err)
+    }
+
+    return pool
+}
+
+func TestPostgresUserRepository(t *testing.T) {
+    url := postgresURL(t)
+    repositorytest.TestUserRepository(t, func(t *testing.T) repository.UserRepository {
+        return repository.NewUserRepository(openPostgres(t, url))
+    })
+}
@@ -0,0 +1,487 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "modernc.org/sqlite"
+    sqlite3 "modernc.org/sqlite/lib"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+const sqliteUserColumns = "id, name, email, password, role, email_verified_at, COALESCE(totp_secret, ''), totp_enabled_at, totp_last_step, created_at, updated_at, last_login_at, deleted_at, version"
+
+// sqliteEpoch is the Unix epoch in the layout the driver writes times in,
+// see userSortColumns for why users who never logged in sort there.
+const sqliteEpoch = "1970-01-01 00:00:00+00:00"
+
+var sqliteUserSortColumns = map[string]string{
+    "id":            "id",
+    "name":          "name",
+    "email":         "email",
+    "created_at":    "created_at",
+    "updated_at":    "updated_at",
+    "last_login_at": "COALESCE(last_login_at, '" + sqliteEpoch + "')",
+}
+
+type sqliteUserRepository struct {
+    db *sql.DB
+}
+
+func NewSQLiteUserRepository(db *sql.DB) UserRepository {
+    return &sqliteUserRepository{db: db}
+}
+
+func (r *sqliteUserRepository) Create(ctx context.Context, u *models.User) error {
+    logging.Debug(ctx, "creating user", "email", u.Email)
+
+    hashedPassword, err := utils.HashPassword(u.Password)
+    if err != nil {
+        logging.Error(ctx, "error hashing password", "error", err)
+        return err
+    }
+
+    if u.Role == "" {
+        u.Role = models.RoleUser
+    }
+
+    now := dbNow()
+    err = sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        `INSERT INTO users (name, email, password, role, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+         RETURNING id, version`,
+        u.Name, u.Email, hashedPassword, u.Role, now, now).
+        Scan(&u.ID, &u.Version)
+    if isSQLiteUniqueViolation(err) {
+        logging.Debug(ctx, "user already exists", "email", u.Email)
+        return ErrUserExists
+    }
+    if err != nil {
+        logging.Error(ctx, "error creating user", "error", err)
+        return err
+    }
+
+    u.Password = hashedPassword
+    u.CreatedAt = now
+    u.UpdatedAt = now
+    logging.Info(ctx, "user created", "email", u.Email)
+    return nil
+}
+
+func (r *sqliteUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+    logging.Debug(ctx, "fetching user", "email", email)
+
+    return r.get(ctx, "LOWER(email)=LOWER(?)", email)
+}
+
+func (r *sqliteUserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+    logging.Debug(ctx, "fetching user", "user_id", id)
+
+    return r.get(ctx, "id=?", id)
+}
+
+func (r *sqliteUserRepository) get(ctx context.Context, cond string, arg interface{}) (*models.User, error) {
+    var u models.User
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        "SELECT "+sqliteUserColumns+" FROM users WHERE "+cond+" AND deleted_at IS NULL", arg).
+        Scan(&u.ID, &u.Name, &u.Email, &u.Password, &u.Role, &u.EmailVerifiedAt, &u.TOTPSecret, &u.TOTPEnabledAt, &u.TOTPLastStep, &u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt, &u.DeletedAt, &u.Version)
+
+    if errors.Is(err, sql.ErrNoRows) {
+        logging.Debug(ctx, "user not found", "key", arg)
+        return nil, ErrUserNotFound
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error fetching user", "error", err)
+        return nil, err
+    }
+
+    return &u, nil
+}
+
+func (r *sqliteUserRepository) List(ctx context.Context, p *models.UserListParams) (*models.UserPage, error) {
+    logging.Debug(ctx, "listing users", "sort", p.Sort, "desc", p.Desc, "limit", p.Limit, "offset", p.Offset)
+
+    sortCol, ok := sqliteUserSortColumns[p.Sort]
+    if !ok {
+        return nil, ErrInvalidSort
+    }
+
+    var conds []string
+    var args []interface{}
+    addCond := func(cond string, values ...interface{}) {
+        conds = append(conds, cond)
+        args = append(args, values...)
+    }
+
+    if !p.IncludeDeleted {
+        conds = append(conds, "deleted_at IS NULL")
+    }
+    if p.EmailContains != "" {
+        addCond(`email LIKE '%' || ? || '%' ESCAPE '\'`, escapeLike(p.EmailContains))
+    }
+    if p.NameContains != "" {
+        addCond(`name LIKE '%' || ? || '%' ESCAPE '\'`, escapeLike(p.NameContains))
+    }
+    if p.Role != "" {
+        addCond("role = ?", p.Role)
+    }
+    if p.CreatedAfter != nil {
+        addCond("created_at > ?", p.CreatedAfter.UTC())
+    }
+    if p.CreatedBefore != nil {
+        addCond("created_at < ?", p.CreatedBefore.UTC())
+    }
+    if p.UpdatedAfter != nil {
+        addCond("updated_at > ?", p.UpdatedAfter.UTC())
+    }
+    if p.UpdatedBefore != nil {
+        addCond("updated_at < ?", p.UpdatedBefore.UTC())
+    }
+    if p.LastLoginAfter != nil {
+        addCond("last_login_at > ?", p.LastLoginAfter.UTC())
+    }
+    if p.LastLoginBefore != nil {
+        addCond("(last_login_at < ? OR last_login_at IS NULL)", p.LastLoginBefore.UTC())
+    }
+
+    var total int64
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM users"+whereClause(conds), args...).Scan(&total)
+    if err != nil {
+        logging.Error(ctx, "error counting users", "error", err)
+        return nil, err
+    }
+
+    cmp, order := ">", "ASC"
+    if p.Desc {
+        cmp, order = "<", "DESC"
+    }
+
+    if p.Cursor != "" {
+        c, err := decodeUserCursor(p)
+        if err != nil {
+            return nil, err
+        }
+
+        if sortCol == "id" {
+            addCond("id "+cmp+" ?", c.ID)
+        } else {
+            value, err := sqliteCursorValue(c)
+            if err != nil {
+                return nil, err
+            }
+            addCond(fmt.Sprintf("(%s, id) %s (?, ?)", sortCol, cmp), value, c.ID)
+        }
+    }
+
+    orderBy := sortCol + " " + order
+    if sortCol != "id" {
+        orderBy += ", id " + order
+    }
+
+    query := "SELECT id, name, email, role, email_verified_at, totp_enabled_at, created_at, updated_at, last_login_at, deleted_at, version FROM users" +
+        whereClause(conds) + " ORDER BY " + orderBy + " LIMIT ?"
+    args = append(args, p.Limit+1)
+    if p.Cursor == "" && p.Offset > 0 {
+        query += " OFFSET ?"
+        args = append(args, p.Offset)
+    }
+
+    rows, err := sqliteConn(ctx, r.db).QueryContext(ctx, query, args...)
+    if err != nil {
+        logging.Error(ctx, "error listing users", "error", err)
+        return nil, err
+    }
+    defer rows.Close()
+
+    users := make([]models.User, 0, p.Limit)
+    for rows.Next() {
+        var u models.User
+        if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.EmailVerifiedAt, &u.TOTPEnabledAt, &u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt, &u.DeletedAt, &u.Version); err != nil {
+            logging.Error(ctx, "error scanning user row", "error", err)
+            return nil, err
+        }
+        users = append(users, u)
+    }
+    if err := rows.Err(); err != nil {
+        logging.Error(ctx, "error iterating user rows", "error", err)
+        return nil, err
+    }
+
+    page := &models.UserPage{Total: total}
+    if len(users) > p.Limit {
+        users = users[:p.Limit]
+        page.NextCursor = encodeUserCursor(p, &users[len(users)-1])
+    }
+    page.Users = users
+
+    logging.Debug(ctx, "users listed", "count", len(users), "total", total)
+    return page, nil
+}
+
+// sqliteCursorValue converts the cursor's sort value into a query argument.
+// Times go through the driver so they are formatted like the stored ones.
+func sqliteCursorValue(c *userCursor) (interface{}, error) {
+    switch c.Sort {
+    case "name", "email":
+        return c.Value, nil
+    }
+
+    t, err := time.Parse(time.RFC3339Nano, c.Value)
+    if err != nil {
+        return nil, ErrInvalidCursor
+    }
+    return t.UTC(), nil
+}
+
+func (r *sqliteUserRepository) Update(ctx context.Context, u *models.User, ifMatch []int64) error {
+    logging.Debug(ctx, "updating user", "user_id", u.ID)
+
+    versionCond, versionArgs := sqliteVersionCond(ifMatch)
+    args := append([]interface{}{u.Name, u.Email, u.Email, dbNow(), u.ID}, versionArgs...)
+
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        `UPDATE users SET name=?, email=?,
+             email_verified_at = CASE WHEN lower(email) = lower(?) THEN email_verified_at END,
+             version=version+1, updated_at=?
+         WHERE id=? AND deleted_at IS NULL`+versionCond+` RETURNING version`,
+        args...).Scan(&u.Version)
+    if errors.Is(err, sql.ErrNoRows) {
+        return r.writeMissError(ctx, u.ID)
+    }
+    if isSQLiteUniqueViolation(err) {
+        return ErrUserExists
+    }
+    if err != nil {
+        logging.Error(ctx, "error updating user", "error", err)
+        return err
+    }
+
+    logging.Info(ctx, "user updated", "user_id", u.ID)
+    return nil
+}
+
+func (r *sqliteUserRepository) Patch(ctx context.Context, id int64, patch *models.UserPatch, ifMatch []int64) (int64, error) {
+    logging.Debug(ctx, "patching user", "user_id", id)
+
+    sets := []string{"version=version+1", "updated_at=?"}
+    args := []interface{}{dbNow()}
+    if patch.Name != nil {
+        sets = append(sets, "name=?")
+        args = append(args, *patch.Name)
+    }
+    if patch.Email != nil {
+        sets = append(sets, "email=?", "email_verified_at = CASE WHEN lower(email) = lower(?) THEN email_verified_at END")
+        args = append(args, *patch.Email, *patch.Email)
+    }
+
+    versionCond, versionArgs := sqliteVersionCond(ifMatch)
+    args = append(append(args, id), versionArgs...)
+
+    var version int64
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        "UPDATE users SET "+strings.Join(sets, ", ")+" WHERE id=? AND deleted_at IS NULL"+versionCond+" RETURNING version",
+        args...).Scan(&version)
+    if errors.Is(err, sql.ErrNoRows) {
+        return 0, r.writeMissError(ctx, id)
+    }
+    if isSQLiteUniqueViolation(err) {
+        return 0, ErrUserExists
+    }
+    if err != nil {
+        logging.Error(ctx, "error patching user", "error", err)
+        return 0, err
+    }
+
+    logging.Info(ctx, "user patched", "user_id", id)
+    return version, nil
+}
+
+func (r *sqliteUserRepository) Delete(ctx context.Context, id int64, ifMatch []int64) error {
+    logging.Debug(ctx, "deleting user", "user_id", id)
+
+    now := dbNow()
+    versionCond, versionArgs := sqliteVersionCond(ifMatch)
+    args := append([]interface{}{now, now, id}, versionArgs...)
+
+    result, err := sqliteConn(ctx, r.db).ExecContext(ctx,
+        "UPDATE users SET deleted_at=?, version=version+1, updated_at=? WHERE id=? AND deleted_at IS NULL"+versionCond,
+        args...)
+    if err != nil {
+        logging.Error(ctx, "error deleting user", "error", err)
+        return err
+    }
+
+    if rowsAffected(result) == 0 {
+        return r.writeMissError(ctx, id)
+    }
+
+    logging.Info(ctx, "user deleted", "user_id", id)
+    return nil
+}
+
+// writeMissError explains why a conditional write touched no rows: either the
+// user does not exist or its version did not match the If-Match precondition.
+func (r *sqliteUserRepository) writeMissError(ctx context.Context, id int64) error {
+    var exists bool
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        "SELECT EXISTS(SELECT 1 FROM users WHERE id=? AND deleted_at IS NULL)", id).
+        Scan(&exists)
+    if err != nil {
+        logging.Error(ctx, "error checking user existence", "error", err)
+        return err
+    }
+
+    if exists {
+        logging.Debug(ctx, "user version mismatch", "user_id", id)
+        return ErrVersionMismatch
+    }
+
+    logging.Debug(ctx, "user not found for write", "user_id", id)
+    return ErrUserNotFound
+}
+
+func (r *sqliteUserRepository) Restore(ctx context.Context, id int64) error {
+    logging.Debug(ctx, "restoring user", "user_id", id)
+
+    result, err := sqliteConn(ctx, r.db).ExecContext(ctx,
+        "UPDATE users SET deleted_at=NULL, version=version+1, updated_at=? WHERE id=? AND deleted_at IS NOT NULL", dbNow(), id)
+    if err != nil {
+        logging.Error(ctx, "error restoring user", "error", err)
+        return err
+    }
+
+    if rowsAffected(result) == 0 {
+        logging.Debug(ctx, "deleted user not found for restore", "user_id", id)
+        return ErrUserNotFound
+    }
+
+    logging.Info(ctx, "user restored", "user_id", id)
+    return nil
+}
+
+func (r *sqliteUserRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+    result, err := sqliteConn(ctx, r.db).ExecContext(ctx, "DELETE FROM users WHERE deleted_at < ?", deletedBefore.UTC())
+    if err != nil {
+        logging.Error(ctx, "error purging deleted users", "error", err)
+        return 0, err
+    }
+
+    return rowsAffected(result), nil
+}
+
+func (r *sqliteUserRepository) RecordLogin(ctx context.Context, id int64) error {
+    _, err := sqliteConn(ctx, r.db).ExecContext(ctx,
+        "UPDATE users SET last_login_at=? WHERE id=? AND deleted_at IS NULL", dbNow(), id)
+    if err != nil {
+        logging.Error(ctx, "error recording login", "user_id", id, "error", err)
+    }
+    return err
+}
+
+func (r *sqliteUserRepository) UpdateRole(ctx context.Context, id int64, role string) error {
+    logging.Debug(ctx, "updating user role", "user_id", id)
+
+    return r.exec(ctx, "updating user role",
+        "UPDATE users SET role=?, version=version+1, updated_at=? WHERE id=? AND deleted_at IS NULL", role, dbNow(), id)
+}
+
+func (r *sqliteUserRepository) UpdatePassword(ctx context.Context, id int64, password string) error {
+    logging.Debug(ctx, "updating user password", "user_id", id)
+
+    hashedPassword, err := utils.HashPassword(password)
+    if err != nil {
+        logging.Error(ctx, "error hashing password", "error", err)
+        return err
+    }
+
+    return r.exec(ctx, "updating user password",
+        "UPDATE users SET password=?, version=version+1, updated_at=? WHERE id=? AND deleted_at IS NULL", hashedPassword, dbNow(), id)
+}
+
+func (r *sqliteUserRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+    logging.Debug(ctx, "marking email verified", "user_id", id)
+
+    now := dbNow()
+    return r.exec(ctx, "marking email verified",
+        "UPDATE users SET email_verified_at = COALESCE(email_verified_at, ?), version=version+1, updated_at=? WHERE id=? AND deleted_at IS NULL", now, now, id)
+}
+
+func (r *sqliteUserRepository) SetTOTPSecret(ctx context.Context, id int64, secret string) error {
+    logging.Debug(ctx, "setting pending TOTP secret", "user_id", id)
+
+    return r.exec(ctx, "setting TOTP secret",
+        "UPDATE users SET totp_secret=?, totp_enabled_at=NULL, totp_last_step=0, version=version+1, updated_at=? WHERE id=? AND deleted_at IS NULL", secret, dbNow(), id)
+}
+
+func (r *sqliteUserRepository) EnableTOTP(ctx context.Context, id int64) error {
+    logging.Debug(ctx, "enabling TOTP", "user_id", id)
+
+    now := dbNow()
+    return r.exec(ctx, "enabling TOTP",
+        "UPDATE users SET totp_enabled_at=?, version=version+1, updated_at=? WHERE id=? AND deleted_at IS NULL AND totp_secret IS NOT NULL", now, now, id)
+}
+
+func (r *sqliteUserRepository) DisableTOTP(ctx context.Context, id int64) error {
+    logging.Debug(ctx, "disabling TOTP", "user_id", id)
+
+    return r.exec(ctx, "disabling TOTP",
+        "UPDATE users SET totp_secret=NULL, totp_enabled_at=NULL, totp_last_step=0, version=version+1, updated_at=? WHERE id=? AND deleted_at IS NULL", dbNow(), id)
+}
+
+func (r *sqliteUserRepository) UpdateTOTPLastStep(ctx context.Context, id int64, step int64) (bool, error) {
+    result, err := sqliteConn(ctx, r.db).ExecContext(ctx,
+        "UPDATE users SET totp_last_step=? WHERE id=? AND deleted_at IS NULL AND totp_last_step < ?", step, id, step)
+    if err != nil {
+        logging.Error(ctx, "error updating TOTP step", "error", err)
+        return false, err
+    }
+
+    return rowsAffected(result) == 1, nil
+}
+
+// exec runs a single-user update and reports ErrUserNotFound when no
+// active user matched.
+func (r *sqliteUserRepository) exec(ctx context.Context, action string, query string, args ...interface{}) error {
+    result, err := sqliteConn(ctx, r.db).ExecContext(ctx, query, args...)
+    if err != nil {
+        logging.Error(ctx, "error "+action, "error", err)
+        return err
+    }
+
+    if rowsAffected(result) == 0 {
+        return ErrUserNotFound
+    }
+    return nil
+}
+
+// sqliteVersionCond is the If-Match condition: nil matches any version.
+func sqliteVersionCond(ifMatch []int64) (string, []interface{}) {
+    if ifMatch == nil {
+        return "", nil
+    }
+    if len(ifMatch) == 0 {
+        return " AND 0", nil
+    }
+
+    args := make([]interface{}, len(ifMatch))
+    for i, v := range ifMatch {
+        args[i] = v
+    }
+    return " AND version IN (?" + strings.Repeat(", ?", len(ifMatch)-1) + ")", args
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+    var sqliteErr *sqlite.Error
+    return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+func rowsAffected(result sql.Result) int64 {
+    n, _ := result.RowsAffected()
+    return n
+}
@@ -0,0 +1,39 @@
+package repository_test
+
+import (
+    "context"
+    "database/sql"
+    "path/filepath"
+    "testing"
+
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+    "github.com/MorozkoArt/go-crud-api/internal/db"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/repository/repositorytest"
+)
+
+// openSQLite opens a migrated database in a temporary directory that is
+// removed when the test ends.
+func openSQLite(t *testing.T) *sql.DB {
+    t.Helper()
+
+    cfg := &config.Config{Database: config.DatabaseConfig{
+        Driver:      "sqlite",
+        Path:        filepath.Join(t.TempDir(), "test.db"),
+        AutoMigrate: true,
+    }}
+
+    sqlDB, err := db.NewSQLiteDB(context.Background(), cfg)
+    if err != nil {
+        t.Fatalf("open SQLite database: %v", err)
+    }
+    t.Cleanup(func() { sqlDB.Close() })
+
+    return sqlDB
+}
+
+func TestSQLiteUserRepository(t *testing.T) {
+    repositorytest.TestUserRepository(t, func(t *testing.T) repository.UserRepository {
+        return repository.NewSQLiteUserRepository(openSQLite(t))
+    })
+}
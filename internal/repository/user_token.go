@@ -0,0 +1,95 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+var (
+    ErrUserTokenNotFound = apperrors.NotFound("user token not found")
+)
+
+type UserTokenRepository interface {
+    Create(ctx context.Context, token *models.UserToken) error
+    Consume(ctx context.Context, tokenHash string, purpose string) (*models.UserToken, error)
+    InvalidateAll(ctx context.Context, userID int64, purpose string) error
+    LatestCreatedAt(ctx context.Context, userID int64, purpose string) (time.Time, error)
+}
+
+type userTokenRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewUserTokenRepository(db *pgxpool.Pool) UserTokenRepository {
+    return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, t *models.UserToken) error {
+    logging.Debug(ctx, "creating user token", "purpose", t.Purpose, "user_id", t.UserID)
+
+    err := pgxConn(ctx, r.db).QueryRow(ctx,
+        "INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+        t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt).
+        Scan(&t.ID, &t.CreatedAt)
+    if err != nil {
+        logging.Error(ctx, "error creating user token", "error", err)
+    }
+
+    return err
+}
+
+func (r *userTokenRepository) Consume(ctx context.Context, tokenHash string, purpose string) (*models.UserToken, error) {
+    var t models.UserToken
+    err := pgxConn(ctx, r.db).QueryRow(ctx,
+        `UPDATE user_tokens SET used_at = NOW()
+         WHERE token_hash=$1 AND purpose=$2 AND used_at IS NULL AND expires_at > NOW()
+         RETURNING id, user_id, purpose, token_hash, expires_at, used_at, created_at`,
+        tokenHash, purpose).
+        Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrUserTokenNotFound
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error consuming user token", "error", err)
+        return nil, err
+    }
+
+    return &t, nil
+}
+
+func (r *userTokenRepository) InvalidateAll(ctx context.Context, userID int64, purpose string) error {
+    _, err := pgxConn(ctx, r.db).Exec(ctx,
+        "UPDATE user_tokens SET used_at = NOW() WHERE user_id=$1 AND purpose=$2 AND used_at IS NULL",
+        userID, purpose)
+    if err != nil {
+        logging.Error(ctx, "error invalidating user tokens", "error", err)
+    }
+
+    return err
+}
+
+func (r *userTokenRepository) LatestCreatedAt(ctx context.Context, userID int64, purpose string) (time.Time, error) {
+    var createdAt *time.Time
+    err := pgxConn(ctx, r.db).QueryRow(ctx,
+        "SELECT MAX(created_at) FROM user_tokens WHERE user_id=$1 AND purpose=$2",
+        userID, purpose).
+        Scan(&createdAt)
+    if err != nil {
+        logging.Error(ctx, "error fetching latest user token", "error", err)
+        return time.Time{}, err
+    }
+
+    if createdAt == nil {
+        return time.Time{}, nil
+    }
+    return *createdAt, nil
+}
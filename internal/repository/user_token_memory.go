@@ -0,0 +1,75 @@
+package repository
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+type memoryUserTokenRepository struct {
+    mu     sync.Mutex
+    nextID int64
+    tokens map[string]*models.UserToken
+}
+
+func NewMemoryUserTokenRepository() UserTokenRepository {
+    return &memoryUserTokenRepository{tokens: make(map[string]*models.UserToken)}
+}
+
+func (r *memoryUserTokenRepository) Create(ctx context.Context, t *models.UserToken) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.nextID++
+    t.ID = r.nextID
+    t.CreatedAt = dbNow()
+
+    stored := *t
+    r.tokens[t.TokenHash] = &stored
+    return nil
+}
+
+func (r *memoryUserTokenRepository) Consume(ctx context.Context, tokenHash string, purpose string) (*models.UserToken, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    now := dbNow()
+    t, ok := r.tokens[tokenHash]
+    if !ok || t.Purpose != purpose || t.UsedAt != nil || !t.ExpiresAt.After(now) {
+        return nil, ErrUserTokenNotFound
+    }
+
+    t.UsedAt = &now
+
+    consumed := *t
+    return &consumed, nil
+}
+
+func (r *memoryUserTokenRepository) InvalidateAll(ctx context.Context, userID int64, purpose string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    now := dbNow()
+    for _, t := range r.tokens {
+        if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+            usedAt := now
+            t.UsedAt = &usedAt
+        }
+    }
+    return nil
+}
+
+func (r *memoryUserTokenRepository) LatestCreatedAt(ctx context.Context, userID int64, purpose string) (time.Time, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    var latest time.Time
+    for _, t := range r.tokens {
+        if t.UserID == userID && t.Purpose == purpose && t.CreatedAt.After(latest) {
+            latest = t.CreatedAt
+        }
+    }
+    return latest, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+type sqliteUserTokenRepository struct {
+    db *sql.DB
+}
+
+func NewSQLiteUserTokenRepository(db *sql.DB) UserTokenRepository {
+    return &sqliteUserTokenRepository{db: db}
+}
+
+func (r *sqliteUserTokenRepository) Create(ctx context.Context, t *models.UserToken) error {
+    logging.Debug(ctx, "creating user token", "purpose", t.Purpose, "user_id", t.UserID)
+
+    createdAt := dbNow()
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        "INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id",
+        t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt.UTC(), createdAt).
+        Scan(&t.ID)
+    if err != nil {
+        logging.Error(ctx, "error creating user token", "error", err)
+        return err
+    }
+
+    t.CreatedAt = createdAt
+    return nil
+}
+
+func (r *sqliteUserTokenRepository) Consume(ctx context.Context, tokenHash string, purpose string) (*models.UserToken, error) {
+    now := dbNow()
+
+    var t models.UserToken
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        `UPDATE user_tokens SET used_at = ?
+         WHERE token_hash=? AND purpose=? AND used_at IS NULL AND expires_at > ?
+         RETURNING id, user_id, purpose, token_hash, expires_at, used_at, created_at`,
+        now, tokenHash, purpose, now).
+        Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrUserTokenNotFound
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error consuming user token", "error", err)
+        return nil, err
+    }
+
+    return &t, nil
+}
+
+func (r *sqliteUserTokenRepository) InvalidateAll(ctx context.Context, userID int64, purpose string) error {
+    _, err := sqliteConn(ctx, r.db).ExecContext(ctx,
+        "UPDATE user_tokens SET used_at = ? WHERE user_id=? AND purpose=? AND used_at IS NULL",
+        dbNow(), userID, purpose)
+    if err != nil {
+        logging.Error(ctx, "error invalidating user tokens", "error", err)
+    }
+
+    return err
+}
+
+func (r *sqliteUserTokenRepository) LatestCreatedAt(ctx context.Context, userID int64, purpose string) (time.Time, error) {
+    var createdAt time.Time
+    err := sqliteConn(ctx, r.db).QueryRowContext(ctx,
+        "SELECT created_at FROM user_tokens WHERE user_id=? AND purpose=? ORDER BY created_at DESC LIMIT 1",
+        userID, purpose).
+        Scan(&createdAt)
+
+    if errors.Is(err, sql.ErrNoRows) {
+        return time.Time{}, nil
+    }
+
+    if err != nil {
+        logging.Error(ctx, "error fetching latest user token", "error", err)
+        return time.Time{}, err
+    }
+
+    return createdAt, nil
+}
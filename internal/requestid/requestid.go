@@ -0,0 +1,28 @@
+package requestid
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+)
+
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+func New() string {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return ""
+    }
+    return hex.EncodeToString(b)
+}
+
+func NewContext(ctx context.Context, id string) context.Context {
+    return context.WithValue(ctx, contextKey{}, id)
+}
+
+func FromContext(ctx context.Context) string {
+    id, _ := ctx.Value(contextKey{}).(string)
+    return id
+}
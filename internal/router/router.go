@@ -1,28 +1,60 @@
 package router
 
 import (
+    "log/slog"
+    "net/http"
+
     "github.com/go-chi/chi/v5"
     "github.com/MorozkoArt/go-crud-api/internal/handlers"
+    "github.com/MorozkoArt/go-crud-api/internal/i18n"
     "github.com/MorozkoArt/go-crud-api/internal/middleware"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/problem"
     "github.com/MorozkoArt/go-crud-api/internal/services"
 )
 
-func NewRouter(userHandler *handlers.UserHandler, authService services.AuthService) *chi.Mux {
+func NewRouter(userHandler *handlers.UserHandler, authHandler *handlers.AuthHandler, authService services.AuthService, translator *i18n.Translator, logger *slog.Logger) *chi.Mux {
     r := chi.NewRouter()
     
-    r.Use(middleware.Logger)
+    r.Use(middleware.RequestID)
+    r.Use(middleware.Locale(translator))
+    r.Use(middleware.Logger(logger))
+
+    r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+        problem.Write(w, r, problem.New(http.StatusNotFound, "Resource not found"))
+    })
+    r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+        problem.Write(w, r, problem.New(http.StatusMethodNotAllowed, "Method not allowed"))
+    })
+
+    r.Get("/.well-known/jwks.json", authHandler.JWKS)
     
     r.Route("/api/users", func(r chi.Router) {
         r.Post("/register", userHandler.Register)
         r.Post("/login", userHandler.Login)
+        r.Post("/login/mfa", userHandler.LoginMFA)
+        r.Post("/token/refresh", userHandler.RefreshToken)
+        r.Post("/password/forgot", userHandler.ForgotPassword)
+        r.Post("/password/reset", userHandler.ResetPassword)
+        r.Get("/verify", userHandler.VerifyEmail)
+        r.Post("/verify/resend", userHandler.ResendVerification)
         
         r.Group(func(r chi.Router) {
             r.Use(middleware.AuthMiddleware(authService))
             
-            r.Get("/", userHandler.GetAllUsers)
+            r.Post("/logout", userHandler.Logout)
+            r.Post("/logout/all", userHandler.LogoutAll)
+            r.With(middleware.RequireRole(models.RoleAdmin)).Get("/", userHandler.ListUsers)
+            r.With(middleware.RequireRole(models.RoleAdmin)).Put("/{id}/role", userHandler.UpdateRole)
+            r.With(middleware.RequireRole(models.RoleAdmin)).Post("/{id}/restore", userHandler.RestoreUser)
             r.Get("/{id}", userHandler.GetUserByID)
             r.Put("/{id}", userHandler.UpdateUser)
+            r.Patch("/{id}", userHandler.PatchUser)
             r.Delete("/{id}", userHandler.DeleteUser)
+            r.Put("/{id}/password", userHandler.ChangePassword)
+            r.Post("/{id}/mfa/totp", userHandler.EnrollTOTP)
+            r.Post("/{id}/mfa/totp/confirm", userHandler.ConfirmTOTP)
+            r.Delete("/{id}/mfa/totp", userHandler.DisableTOTP)
         })
     })
     
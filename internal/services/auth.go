@@ -1,30 +1,258 @@
 package services
 
 import (
+    "context"
+    "errors"
     "time"
 
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
     "github.com/MorozkoArt/go-crud-api/internal/utils"
 )
 
+var (
+    ErrInvalidRefreshToken = apperrors.Unauthorized("invalid or expired refresh token")
+    ErrRefreshTokenReused  = apperrors.Unauthorized("refresh token reuse detected")
+    ErrTokenRevoked        = apperrors.Unauthorized("token has been revoked")
+    ErrInvalidTokenUse     = apperrors.Unauthorized("token cannot be used for this purpose")
+)
+
 type AuthService interface {
-    GenerateToken(userID int64, email string) (string, error)
-    ValidateToken(tokenString string) (*utils.Claims, error)
+    IssueTokens(ctx context.Context, user *models.User, familyID string, mfa bool) (*models.TokenPair, error)
+    IssueMFAChallenge(ctx context.Context, user *models.User) (string, error)
+    ValidateMFAChallenge(ctx context.Context, tokenString string) (*utils.Claims, error)
+    ConsumeRefreshToken(ctx context.Context, refreshToken string) (*models.RefreshToken, error)
+    ValidateToken(ctx context.Context, tokenString string) (*utils.Claims, error)
+    RevokeToken(ctx context.Context, claims *utils.Claims) error
+    RevokeRefreshToken(ctx context.Context, userID int64, refreshToken string) error
+    RevokeAllSessions(ctx context.Context, userID int64) error
+    JWKS() utils.JWKS
 }
 
 type authService struct {
-    jwtService *utils.JWTService
+    jwtService      *utils.JWTService
+    refreshRepo     repository.RefreshTokenRepository
+    revocationStore repository.RevocationStore
+    accessExpiry    time.Duration
+    refreshExpiry   time.Duration
+    mfaExpiry       time.Duration
+}
+
+func NewJWTService(cfg config.AuthConfig) (*utils.JWTService, error) {
+    if cfg.Signing.Algorithm == "" || cfg.Signing.Algorithm == "HS256" {
+        return utils.NewJWTService(cfg.JWTSecret, cfg.TokenExpiry), nil
+    }
+
+    keys := make([]*utils.SigningKey, 0, len(cfg.Signing.Keys))
+    for _, k := range cfg.Signing.Keys {
+        algorithm := k.Algorithm
+        if algorithm == "" {
+            algorithm = cfg.Signing.Algorithm
+        }
+
+        key, err := utils.LoadSigningKey(k.KID, algorithm, k.PrivateKeyFile, k.PublicKeyFile)
+        if err != nil {
+            return nil, err
+        }
+        keys = append(keys, key)
+    }
+
+    return utils.NewJWTServiceWithKeys(keys, cfg.Signing.ActiveKID, cfg.TokenExpiry)
 }
 
-func NewAuthService(secretKey string, expiry time.Duration) AuthService {
+func NewAuthService(cfg config.AuthConfig, jwtService *utils.JWTService, refreshRepo repository.RefreshTokenRepository, revocationStore repository.RevocationStore) AuthService {
     return &authService{
-        jwtService: utils.NewJWTService(secretKey, expiry),
+        jwtService:      jwtService,
+        refreshRepo:     refreshRepo,
+        revocationStore: revocationStore,
+        accessExpiry:    cfg.TokenExpiry,
+        refreshExpiry:   cfg.RefreshTokenExpiry,
+        mfaExpiry:       cfg.MFAChallengeExpiry,
+    }
+}
+
+func (s *authService) IssueTokens(ctx context.Context, user *models.User, familyID string, mfa bool) (*models.TokenPair, error) {
+    generation, err := s.revocationStore.UserTokenGeneration(ctx, user.ID)
+    if err != nil {
+        return nil, err
+    }
+
+    accessToken, err := s.jwtService.GenerateToken(user.ID, user.Email, user.Role, mfa, generation)
+    if err != nil {
+        return nil, err
+    }
+
+    if familyID == "" {
+        familyID, err = utils.GenerateRandomToken(16)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    refreshToken, err := utils.GenerateRandomToken(32)
+    if err != nil {
+        return nil, err
+    }
+
+    err = s.refreshRepo.Create(ctx, &models.RefreshToken{
+        UserID:    user.ID,
+        FamilyID:  familyID,
+        TokenHash: utils.HashToken(refreshToken),
+        ExpiresAt: time.Now().Add(s.refreshExpiry),
+        MFA:       mfa,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return &models.TokenPair{
+        AccessToken:  accessToken,
+        RefreshToken: refreshToken,
+        ExpiresIn:    int64(s.accessExpiry.Seconds()),
+    }, nil
+}
+
+// IssueMFAChallenge binds the challenge to the current session generation,
+// so logging out everywhere or changing the password also voids challenges
+// that are still pending.
+func (s *authService) IssueMFAChallenge(ctx context.Context, user *models.User) (string, error) {
+    generation, err := s.revocationStore.UserTokenGeneration(ctx, user.ID)
+    if err != nil {
+        return "", err
+    }
+
+    return s.jwtService.GenerateMFAChallenge(user.ID, user.Email, generation, s.mfaExpiry)
+}
+
+// ValidateMFAChallenge accepts a challenge that has not been revoked. The
+// caller revokes it with RevokeToken once it is used, so it works only once.
+func (s *authService) ValidateMFAChallenge(ctx context.Context, tokenString string) (*utils.Claims, error) {
+    claims, err := s.jwtService.ValidateToken(tokenString)
+    if err != nil {
+        return nil, err
+    }
+
+    if claims.TokenUse != utils.TokenUseMFAChallenge {
+        return nil, ErrInvalidTokenUse
+    }
+
+    if err := s.checkRevoked(ctx, claims); err != nil {
+        return nil, err
+    }
+
+    return claims, nil
+}
+
+func (s *authService) ConsumeRefreshToken(ctx context.Context, refreshToken string) (*models.RefreshToken, error) {
+    tokenHash := utils.HashToken(refreshToken)
+
+    token, err := s.refreshRepo.MarkUsed(ctx, tokenHash)
+    if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+        existing, getErr := s.refreshRepo.GetByHash(ctx, tokenHash)
+        if errors.Is(getErr, repository.ErrRefreshTokenNotFound) {
+            return nil, ErrInvalidRefreshToken
+        }
+        if getErr != nil {
+            return nil, getErr
+        }
+
+        // A revoked token is dead whether or not it was used before, e.g.
+        // after a logout; only a used token that is still live is reuse.
+        if existing.UsedAt == nil || existing.RevokedAt != nil {
+            return nil, ErrInvalidRefreshToken
+        }
+
+        logging.Warn(ctx, "refresh token reuse detected, revoking family", "user_id", existing.UserID)
+        if err := s.refreshRepo.RevokeFamily(ctx, existing.FamilyID); err != nil {
+            return nil, err
+        }
+        return nil, ErrRefreshTokenReused
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    if time.Now().After(token.ExpiresAt) {
+        return nil, ErrInvalidRefreshToken
+    }
+
+    return token, nil
+}
+
+func (s *authService) ValidateToken(ctx context.Context, tokenString string) (*utils.Claims, error) {
+    claims, err := s.jwtService.ValidateToken(tokenString)
+    if err != nil {
+        return nil, err
+    }
+
+    if claims.TokenUse != utils.TokenUseAccess {
+        return nil, ErrInvalidTokenUse
+    }
+
+    if err := s.checkRevoked(ctx, claims); err != nil {
+        return nil, err
+    }
+
+    return claims, nil
+}
+
+// checkRevoked fails with ErrTokenRevoked if the token itself was revoked or
+// was issued before the user's sessions were last revoked.
+func (s *authService) checkRevoked(ctx context.Context, claims *utils.Claims) error {
+    revoked, err := s.revocationStore.IsTokenRevoked(ctx, claims.ID)
+    if err != nil {
+        return err
     }
+    if revoked {
+        return ErrTokenRevoked
+    }
+
+    generation, err := s.revocationStore.UserTokenGeneration(ctx, claims.UserID)
+    if err != nil {
+        return err
+    }
+    if claims.Generation < generation {
+        return ErrTokenRevoked
+    }
+
+    return nil
+}
+
+func (s *authService) RevokeToken(ctx context.Context, claims *utils.Claims) error {
+    expiresAt := time.Now().Add(s.accessExpiry)
+    if claims.ExpiresAt != nil {
+        expiresAt = claims.ExpiresAt.Time
+    }
+
+    return s.revocationStore.RevokeToken(ctx, claims.ID, expiresAt)
 }
 
-func (s *authService) GenerateToken(userID int64, email string) (string, error) {
-    return s.jwtService.GenerateToken(userID, email)
+func (s *authService) RevokeRefreshToken(ctx context.Context, userID int64, refreshToken string) error {
+    token, err := s.refreshRepo.GetByHash(ctx, utils.HashToken(refreshToken))
+    if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+        return ErrInvalidRefreshToken
+    }
+    if err != nil {
+        return err
+    }
+    if token.UserID != userID {
+        return ErrInvalidRefreshToken
+    }
+
+    return s.refreshRepo.RevokeFamily(ctx, token.FamilyID)
 }
 
-func (s *authService) ValidateToken(tokenString string) (*utils.Claims, error) {
-    return s.jwtService.ValidateToken(tokenString)
-}
\ No newline at end of file
+func (s *authService) RevokeAllSessions(ctx context.Context, userID int64) error {
+    if err := s.refreshRepo.RevokeAllForUser(ctx, userID); err != nil {
+        return err
+    }
+
+    return s.revocationStore.RevokeUserTokens(ctx, userID)
+}
+
+func (s *authService) JWKS() utils.JWKS {
+    return s.jwtService.JWKS()
+}
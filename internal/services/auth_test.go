@@ -0,0 +1,96 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+func newTestAuthService() AuthService {
+    cfg := config.AuthConfig{TokenExpiry: time.Minute, RefreshTokenExpiry: time.Hour, MFAChallengeExpiry: time.Minute}
+    return NewAuthService(cfg, utils.NewJWTService("test-secret", cfg.TokenExpiry),
+        repository.NewMemoryRefreshTokenRepository(), repository.NewMemoryRevocationStore())
+}
+
+func TestConsumeRefreshToken(t *testing.T) {
+    ctx := context.Background()
+    s := newTestAuthService()
+    user := &models.User{ID: 1, Email: "alice@example.com"}
+
+    issue := func() string {
+        t.Helper()
+        tokens, err := s.IssueTokens(ctx, user, "", false)
+        if err != nil {
+            t.Fatalf("IssueTokens: %v", err)
+        }
+        return tokens.RefreshToken
+    }
+    consume := func(name, token string, want error) {
+        t.Helper()
+        if _, err := s.ConsumeRefreshToken(ctx, token); !errors.Is(err, want) {
+            t.Errorf("ConsumeRefreshToken of %s: err = %v, want %v", name, err, want)
+        }
+    }
+
+    used := issue()
+    consume("a new token", used, nil)
+    consume("a used token", used, ErrRefreshTokenReused)
+    consume("a token of a family revoked for reuse", used, ErrInvalidRefreshToken)
+
+    loggedOut := issue()
+    if err := s.RevokeRefreshToken(ctx, user.ID, loggedOut); err != nil {
+        t.Fatalf("RevokeRefreshToken: %v", err)
+    }
+    consume("a revoked token", loggedOut, ErrInvalidRefreshToken)
+
+    usedThenLoggedOut := issue()
+    consume("a new token", usedThenLoggedOut, nil)
+    if err := s.RevokeAllSessions(ctx, user.ID); err != nil {
+        t.Fatalf("RevokeAllSessions: %v", err)
+    }
+    consume("a used token revoked by logout", usedThenLoggedOut, ErrInvalidRefreshToken)
+
+    consume("an unknown token", "unknown", ErrInvalidRefreshToken)
+}
+
+func TestMFAChallengeRevocation(t *testing.T) {
+    ctx := context.Background()
+    s := newTestAuthService()
+    user := &models.User{ID: 1, Email: "alice@example.com"}
+
+    issue := func() string {
+        t.Helper()
+        token, err := s.IssueMFAChallenge(ctx, user)
+        if err != nil {
+            t.Fatalf("IssueMFAChallenge: %v", err)
+        }
+        return token
+    }
+    validate := func(name, token string, want error) *utils.Claims {
+        t.Helper()
+        claims, err := s.ValidateMFAChallenge(ctx, token)
+        if !errors.Is(err, want) {
+            t.Fatalf("ValidateMFAChallenge of %s: err = %v, want %v", name, err, want)
+        }
+        return claims
+    }
+
+    used := issue()
+    if err := s.RevokeToken(ctx, validate("a new challenge", used, nil)); err != nil {
+        t.Fatalf("RevokeToken: %v", err)
+    }
+    validate("a used challenge", used, ErrTokenRevoked)
+
+    pending := issue()
+    if err := s.RevokeAllSessions(ctx, user.ID); err != nil {
+        t.Fatalf("RevokeAllSessions: %v", err)
+    }
+    validate("a challenge issued before RevokeAllSessions", pending, ErrTokenRevoked)
+    validate("a challenge issued after RevokeAllSessions", issue(), nil)
+}
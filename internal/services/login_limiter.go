@@ -0,0 +1,170 @@
+package services
+
+import (
+    "context"
+    "strings"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+)
+
+var (
+    ErrAccountLocked   = apperrors.Locked("account is temporarily locked due to too many failed login attempts")
+    ErrTooManyAttempts = apperrors.RateLimited("too many login attempts, try again later")
+)
+
+type LoginLimiter struct {
+    store repository.LoginAttemptStore
+    cfg   config.LockoutConfig
+}
+
+func NewLoginLimiter(store repository.LoginAttemptStore, cfg config.LockoutConfig) *LoginLimiter {
+    return &LoginLimiter{store: store, cfg: cfg}
+}
+
+func (l *LoginLimiter) Check(ctx context.Context, email, ip string) error {
+    now := time.Now()
+
+    emailAttempt, err := l.store.Get(ctx, emailKey(email))
+    if err != nil {
+        return err
+    }
+
+    if wait, locked := l.emailDelay(emailAttempt, now); wait > 0 {
+        if locked {
+            return &apperrors.RetryAfterError{Err: ErrAccountLocked, RetryAfter: wait}
+        }
+        return &apperrors.RetryAfterError{Err: ErrTooManyAttempts, RetryAfter: wait}
+    }
+
+    if ip == "" {
+        return nil
+    }
+
+    ipAttempt, err := l.store.Get(ctx, ipKey(ip))
+    if err != nil {
+        return err
+    }
+
+    if wait := l.ipDelay(ipAttempt, now); wait > 0 {
+        return &apperrors.RetryAfterError{Err: ErrTooManyAttempts, RetryAfter: wait}
+    }
+
+    return nil
+}
+
+// Reserve counts a login attempt against the email and IP limits before the
+// credentials are verified. The store refuses the reservation once a limit
+// is reached, so a burst of parallel requests cannot get more guesses than
+// the limit allows. A reserved attempt counts as a failure unless Success or
+// Release is called.
+func (l *LoginLimiter) Reserve(ctx context.Context, email, ip string) error {
+    if err := l.Check(ctx, email, ip); err != nil {
+        return err
+    }
+
+    emailLimit := models.LoginAttemptLimit{MaxFailures: l.cfg.MaxAttempts, Window: l.cfg.Window, Lockout: l.cfg.LockoutDuration}
+    attempt, ok, err := l.store.Reserve(ctx, emailKey(email), emailLimit)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        logging.Warn(ctx, "account locked", "failures", attempt.Failures, "email", email)
+        return &apperrors.RetryAfterError{Err: ErrAccountLocked, RetryAfter: l.lockoutWait(attempt)}
+    }
+
+    if ip == "" {
+        return nil
+    }
+
+    ipLimit := models.LoginAttemptLimit{MaxFailures: l.cfg.IPMaxAttempts, Window: l.cfg.Window, Lockout: l.cfg.LockoutDuration}
+    attempt, ok, err = l.store.Reserve(ctx, ipKey(ip), ipLimit)
+    if err == nil && !ok {
+        err = &apperrors.RetryAfterError{Err: ErrTooManyAttempts, RetryAfter: l.lockoutWait(attempt)}
+    }
+    if err != nil {
+        if releaseErr := l.store.Release(ctx, emailKey(email)); releaseErr != nil {
+            return releaseErr
+        }
+        return err
+    }
+
+    return nil
+}
+
+// Success clears the failures of the account and takes back the reserved
+// IP attempt, since only failures count against the IP limit.
+func (l *LoginLimiter) Success(ctx context.Context, email, ip string) error {
+    if err := l.store.Reset(ctx, emailKey(email)); err != nil {
+        return err
+    }
+
+    return l.releaseIP(ctx, ip)
+}
+
+// Release takes back a reserved attempt that was neither a success nor a
+// failure, e.g. a correct password when the login still needs a second
+// factor. Failures made before it are kept along with the time of the last
+// one, so their delay is not started again.
+func (l *LoginLimiter) Release(ctx context.Context, email, ip string) error {
+    if err := l.store.Release(ctx, emailKey(email)); err != nil {
+        return err
+    }
+
+    return l.releaseIP(ctx, ip)
+}
+
+func (l *LoginLimiter) releaseIP(ctx context.Context, ip string) error {
+    if ip == "" {
+        return nil
+    }
+
+    return l.store.Release(ctx, ipKey(ip))
+}
+
+// lockoutWait is how long a refused reservation has to wait. It is at least
+// a second, so that Retry-After is never zero.
+func (l *LoginLimiter) lockoutWait(a *models.LoginAttempt) time.Duration {
+    wait := time.Until(a.LastFailureAt.Add(l.cfg.LockoutDuration))
+    if wait < time.Second {
+        wait = time.Second
+    }
+    return wait
+}
+
+func (l *LoginLimiter) emailDelay(a *models.LoginAttempt, now time.Time) (time.Duration, bool) {
+    if a.Failures == 0 || now.Sub(a.LastFailureAt) > l.cfg.Window {
+        return 0, false
+    }
+
+    if l.cfg.MaxAttempts > 0 && a.Failures >= l.cfg.MaxAttempts {
+        return a.LastFailureAt.Add(l.cfg.LockoutDuration).Sub(now), true
+    }
+
+    delay := l.cfg.BaseDelay << uint(a.Failures-1)
+    if delay > l.cfg.MaxDelay || delay <= 0 {
+        delay = l.cfg.MaxDelay
+    }
+
+    return a.LastFailureAt.Add(delay).Sub(now), false
+}
+
+func (l *LoginLimiter) ipDelay(a *models.LoginAttempt, now time.Time) time.Duration {
+    if l.cfg.IPMaxAttempts <= 0 || a.Failures < l.cfg.IPMaxAttempts || now.Sub(a.LastFailureAt) > l.cfg.Window {
+        return 0
+    }
+
+    return a.LastFailureAt.Add(l.cfg.LockoutDuration).Sub(now)
+}
+
+func emailKey(email string) string {
+    return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+    return "ip:" + ip
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+    "context"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+    "github.com/MorozkoArt/go-crud-api/internal/db"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+)
+
+func TestLoginLimiterReleaseRestoresLastFailure(t *testing.T) {
+    stores := []struct {
+        name string
+        open func(t *testing.T) repository.LoginAttemptStore
+    }{
+        {"memory", func(t *testing.T) repository.LoginAttemptStore {
+            return repository.NewMemoryLoginAttemptStore()
+        }},
+        {"sqlite", func(t *testing.T) repository.LoginAttemptStore {
+            cfg := &config.Config{Database: config.DatabaseConfig{
+                Driver:      "sqlite",
+                Path:        filepath.Join(t.TempDir(), "test.db"),
+                AutoMigrate: true,
+            }}
+            sqlDB, err := db.NewSQLiteDB(context.Background(), cfg)
+            if err != nil {
+                t.Fatalf("open SQLite database: %v", err)
+            }
+            t.Cleanup(func() { sqlDB.Close() })
+            return repository.NewSQLiteLoginAttemptStore(sqlDB)
+        }},
+    }
+
+    const baseDelay = 100 * time.Millisecond
+
+    for _, st := range stores {
+        t.Run(st.name, func(t *testing.T) {
+            ctx := context.Background()
+            l := NewLoginLimiter(st.open(t), config.LockoutConfig{
+                MaxAttempts:     5,
+                IPMaxAttempts:   20,
+                BaseDelay:       baseDelay,
+                MaxDelay:        time.Second,
+                LockoutDuration: time.Minute,
+                Window:          time.Hour,
+            })
+
+            // One failed attempt, then wait out its delay.
+            if err := l.Reserve(ctx, "alice@example.com", "192.0.2.1"); err != nil {
+                t.Fatalf("Reserve: %v", err)
+            }
+            time.Sleep(baseDelay + 50*time.Millisecond)
+
+            // A correct password that still needs a second factor must not
+            // start the delay again, or the second factor is throttled.
+            if err := l.Reserve(ctx, "alice@example.com", "192.0.2.1"); err != nil {
+                t.Fatalf("Reserve after the delay: %v", err)
+            }
+            if err := l.Release(ctx, "alice@example.com", "192.0.2.1"); err != nil {
+                t.Fatalf("Release: %v", err)
+            }
+            if err := l.Check(ctx, "alice@example.com", "192.0.2.1"); err != nil {
+                t.Errorf("Check after Release: %v", err)
+            }
+        })
+    }
+}
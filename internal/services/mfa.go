@@ -0,0 +1,234 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+const (
+    recoveryCodeCount = 10
+    totpSkew          = 1
+)
+
+var (
+    ErrMFAAlreadyEnabled = apperrors.Conflict("two-factor authentication is already enabled")
+    ErrMFANotEnrolled    = apperrors.Conflict("two-factor authentication is not enrolled")
+    ErrInvalidMFACode    = apperrors.Validation("invalid two-factor authentication code")
+    ErrInvalidMFAToken   = apperrors.Unauthorized("invalid or expired MFA token")
+)
+
+func (s *userService) EnrollTOTP(ctx context.Context, actor models.Principal, id int64) (*models.TOTPEnrollment, error) {
+    logging.Debug(ctx, "enrolling TOTP", "user_id", id)
+
+    if actor.UserID != id {
+        return nil, ErrForbidden
+    }
+
+    user, err := s.userRepo.GetByID(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    if user.TOTPEnabledAt != nil {
+        return nil, ErrMFAAlreadyEnabled
+    }
+
+    secret, err := utils.GenerateTOTPSecret()
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.userRepo.SetTOTPSecret(ctx, id, secret); err != nil {
+        return nil, err
+    }
+
+    return &models.TOTPEnrollment{
+        Secret: secret,
+        URI:    utils.TOTPURI(s.authConfig.TOTPIssuer, user.Email, secret),
+    }, nil
+}
+
+func (s *userService) ConfirmTOTP(ctx context.Context, actor models.Principal, id int64, req *models.ConfirmTOTPRequest) ([]string, error) {
+    logging.Debug(ctx, "confirming TOTP", "user_id", id)
+
+    if actor.UserID != id {
+        return nil, ErrForbidden
+    }
+
+    user, err := s.userRepo.GetByID(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    if user.TOTPEnabledAt != nil {
+        return nil, ErrMFAAlreadyEnabled
+    }
+    if user.TOTPSecret == "" {
+        return nil, ErrMFANotEnrolled
+    }
+
+    var codes []string
+    err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+        if err := s.verifyTOTP(ctx, user, req.Code); err != nil {
+            return err
+        }
+
+        if err := s.userRepo.EnableTOTP(ctx, id); err != nil {
+            return err
+        }
+
+        var err error
+        codes, err = s.generateRecoveryCodes(ctx, id)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    logging.Info(ctx, "TOTP enabled", "user_id", id)
+    return codes, nil
+}
+
+func (s *userService) DisableTOTP(ctx context.Context, actor models.Principal, id int64, req *models.DisableTOTPRequest) error {
+    logging.Debug(ctx, "disabling TOTP", "user_id", id)
+
+    if actor.UserID != id {
+        return ErrForbidden
+    }
+
+    user, err := s.userRepo.GetByID(ctx, id)
+    if err != nil {
+        return err
+    }
+
+    if user.TOTPEnabledAt == nil {
+        return ErrMFANotEnrolled
+    }
+
+    return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+        if err := s.verifySecondFactor(ctx, user, req.Code, req.RecoveryCode); err != nil {
+            return err
+        }
+
+        if err := s.userRepo.DisableTOTP(ctx, id); err != nil {
+            return err
+        }
+
+        return s.recoveryCodeRepo.DeleteAll(ctx, id)
+    })
+}
+
+func (s *userService) LoginMFA(ctx context.Context, req *models.MFALoginRequest) (*models.LoginResult, error) {
+    logging.Debug(ctx, "MFA login attempt")
+
+    claims, err := s.authService.ValidateMFAChallenge(ctx, req.MFAToken)
+    if err != nil {
+        logging.Info(ctx, "MFA login failed", "error", err)
+        return nil, ErrInvalidMFAToken
+    }
+
+    user, err := s.userRepo.GetByID(ctx, claims.UserID)
+    if errors.Is(err, repository.ErrUserNotFound) {
+        return nil, ErrInvalidMFAToken
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    if user.TOTPEnabledAt == nil {
+        return nil, ErrInvalidMFAToken
+    }
+
+    if err := s.loginLimiter.Reserve(ctx, claims.Email, req.ClientIP); err != nil {
+        logging.Warn(ctx, "MFA login throttled", "user_id", user.ID)
+        return nil, err
+    }
+
+    if err := s.verifySecondFactor(ctx, user, req.Code, req.RecoveryCode); err != nil {
+        if errors.Is(err, ErrInvalidMFACode) {
+            logging.Info(ctx, "MFA login failed", "user_id", user.ID, "reason", "invalid code")
+            return nil, apperrors.Wrap(apperrors.ErrUnauthorized, err)
+        }
+        if releaseErr := s.loginLimiter.Release(ctx, claims.Email, req.ClientIP); releaseErr != nil {
+            return nil, releaseErr
+        }
+        return nil, err
+    }
+
+    if err := s.loginLimiter.Success(ctx, claims.Email, req.ClientIP); err != nil {
+        return nil, err
+    }
+
+    // The challenge has done its job; revoking it keeps it from being used
+    // for another login with a later code.
+    if err := s.authService.RevokeToken(ctx, claims); err != nil {
+        return nil, err
+    }
+
+    tokens, err := s.authService.IssueTokens(ctx, user, "", true)
+    if err != nil {
+        return nil, err
+    }
+
+    s.recordLogin(ctx, user)
+
+    logging.Info(ctx, "MFA login successful", "user_id", user.ID)
+    response := user.ToResponse()
+    return &models.LoginResult{User: &response, Tokens: tokens}, nil
+}
+
+func (s *userService) verifySecondFactor(ctx context.Context, user *models.User, code, recoveryCode string) error {
+    if code != "" {
+        return s.verifyTOTP(ctx, user, code)
+    }
+
+    err := s.recoveryCodeRepo.Consume(ctx, user.ID, utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode)))
+    if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+        return ErrInvalidMFACode
+    }
+    return err
+}
+
+func (s *userService) verifyTOTP(ctx context.Context, user *models.User, code string) error {
+    step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now(), totpSkew)
+    if !ok {
+        return ErrInvalidMFACode
+    }
+
+    fresh, err := s.userRepo.UpdateTOTPLastStep(ctx, user.ID, step)
+    if err != nil {
+        return err
+    }
+    if !fresh {
+        return ErrInvalidMFACode
+    }
+
+    return nil
+}
+
+func (s *userService) generateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+    codes := make([]string, 0, recoveryCodeCount)
+    hashes := make([]string, 0, recoveryCodeCount)
+
+    for i := 0; i < recoveryCodeCount; i++ {
+        code, err := utils.GenerateRecoveryCode()
+        if err != nil {
+            return nil, err
+        }
+        codes = append(codes, code)
+        hashes = append(hashes, utils.HashToken(code))
+    }
+
+    if err := s.recoveryCodeRepo.Replace(ctx, userID, hashes); err != nil {
+        return nil, err
+    }
+
+    return codes, nil
+}
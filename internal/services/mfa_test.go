@@ -0,0 +1,54 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+func TestVerifyTOTPRejectsReplayedSteps(t *testing.T) {
+    ctx := context.Background()
+    repo := repository.NewMemoryUserRepository()
+    s := &userService{userRepo: repo}
+
+    user := &models.User{Name: "Alice", Email: "alice@example.com", Password: "secret123"}
+    if err := repo.Create(ctx, user); err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+
+    secret, err := utils.GenerateTOTPSecret()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := repo.SetTOTPSecret(ctx, user.ID, secret); err != nil {
+        t.Fatalf("SetTOTPSecret: %v", err)
+    }
+    user.TOTPSecret = secret
+
+    step := utils.TOTPStep(time.Now())
+    code := func(step int64) string {
+        c, err := utils.TOTPCode(secret, step)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return c
+    }
+
+    if err := s.verifyTOTP(ctx, user, code(step)); err != nil {
+        t.Fatalf("verifyTOTP of a fresh code: %v", err)
+    }
+    if err := s.verifyTOTP(ctx, user, code(step)); !errors.Is(err, ErrInvalidMFACode) {
+        t.Errorf("verifyTOTP of a replayed code = %v, want ErrInvalidMFACode", err)
+    }
+    if err := s.verifyTOTP(ctx, user, code(step-1)); !errors.Is(err, ErrInvalidMFACode) {
+        t.Errorf("verifyTOTP of an older step = %v, want ErrInvalidMFACode", err)
+    }
+    if err := s.verifyTOTP(ctx, user, code(step+1)); err != nil {
+        t.Errorf("verifyTOTP of the next step: %v", err)
+    }
+}
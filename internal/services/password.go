@@ -0,0 +1,130 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/url"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/mailer"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+var (
+    ErrInvalidResetToken      = apperrors.Validation("invalid or expired password reset token")
+    ErrInvalidCurrentPassword = apperrors.Validation("current password is incorrect")
+)
+
+func (s *userService) ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error {
+    logging.Debug(ctx, "password reset requested", "email", req.Email)
+
+    user, err := s.userRepo.GetByEmail(ctx, req.Email)
+    if errors.Is(err, repository.ErrUserNotFound) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+
+    if err := s.userTokenRepo.InvalidateAll(ctx, user.ID, models.TokenPurposePasswordReset); err != nil {
+        return err
+    }
+
+    token, err := utils.GenerateRandomToken(32)
+    if err != nil {
+        return err
+    }
+
+    err = s.userTokenRepo.Create(ctx, &models.UserToken{
+        UserID:    user.ID,
+        Purpose:   models.TokenPurposePasswordReset,
+        TokenHash: utils.HashToken(token),
+        ExpiresAt: time.Now().Add(s.authConfig.PasswordResetExpiry),
+    })
+    if err != nil {
+        return err
+    }
+
+    link := s.authConfig.PasswordResetURL + "?token=" + url.QueryEscape(token)
+
+    err = s.mailer.Send(ctx, mailer.Message{
+        To:      user.Email,
+        Subject: "Password reset",
+        Body: fmt.Sprintf(
+            "Hello, %s!\n\nTo reset your password, open the link below:\n%s\n\nThe link expires in %s. If you did not request a password reset, ignore this email.\n",
+            user.Name, link, s.authConfig.PasswordResetExpiry),
+    })
+    if err != nil {
+        logging.Error(ctx, "failed to send password reset email", "error", err)
+        return err
+    }
+
+    return nil
+}
+
+func (s *userService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+    logging.Debug(ctx, "resetting password")
+
+    // The token is consumed in the same transaction, so it stays usable if
+    // the password could not be changed.
+    return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+        token, err := s.userTokenRepo.Consume(ctx, utils.HashToken(req.Token), models.TokenPurposePasswordReset)
+        if errors.Is(err, repository.ErrUserTokenNotFound) {
+            return ErrInvalidResetToken
+        }
+        if err != nil {
+            return err
+        }
+
+        if err := s.userRepo.UpdatePassword(ctx, token.UserID, req.Password); err != nil {
+            return err
+        }
+
+        logging.Info(ctx, "password reset", "user_id", token.UserID)
+        return s.authService.RevokeAllSessions(ctx, token.UserID)
+    })
+}
+
+func (s *userService) ChangePassword(ctx context.Context, actor models.Principal, id int64, req *models.ChangePasswordRequest) (*models.TokenPair, error) {
+    logging.Debug(ctx, "changing password", "user_id", id)
+
+    if actor.UserID != id {
+        return nil, ErrForbidden
+    }
+
+    user, err := s.userRepo.GetByID(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+        logging.Info(ctx, "password change failed", "user_id", id, "reason", "invalid current password")
+        return nil, ErrInvalidCurrentPassword
+    }
+
+    var tokens *models.TokenPair
+    err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+        if err := s.userRepo.UpdatePassword(ctx, id, req.NewPassword); err != nil {
+            return err
+        }
+
+        if err := s.authService.RevokeAllSessions(ctx, id); err != nil {
+            return err
+        }
+
+        var err error
+        tokens, err = s.authService.IssueTokens(ctx, user, "", actor.MFA)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    logging.Info(ctx, "password changed", "user_id", id)
+    return tokens, nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "reflect"
+
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+var ErrUnsupportedPatchType = apperrors.Validation("unsupported patch content type")
+
+var patchableUserFields = map[string]bool{
+    "name":  true,
+    "email": true,
+}
+
+func (s *userService) PatchUser(ctx context.Context, actor models.Principal, id int64, req *models.PatchRequest) (*models.UserResponse, error) {
+    logging.Debug(ctx, "patching user", "user_id", id)
+
+    if !s.policy.CanAccessUser(actor, id) {
+        return nil, ErrForbidden
+    }
+
+    user, err := s.userRepo.GetByID(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    // The patch is computed against this snapshot, so a client precondition
+    // is checked here and then pinned to the snapshot version on write.
+    var ifMatch []int64
+    if len(req.IfMatch) > 0 {
+        if !containsVersion(req.IfMatch, user.Version) {
+            return nil, repository.ErrVersionMismatch
+        }
+        ifMatch = []int64{user.Version}
+    }
+
+    original, err := json.Marshal(user.ToResponse())
+    if err != nil {
+        return nil, err
+    }
+
+    var patched []byte
+    switch req.ContentType {
+    case models.MergePatchContentType:
+        patched, err = utils.ApplyMergePatch(original, req.Body)
+    case models.JSONPatchContentType:
+        patched, err = utils.ApplyJSONPatch(original, req.Body)
+    default:
+        return nil, ErrUnsupportedPatchType
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    patch, err := diffUserPatch(original, patched)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := utils.ValidateStruct(patch); err != nil {
+        return nil, err
+    }
+
+    if patch.IsEmpty() {
+        response := user.ToResponse()
+        return &response, nil
+    }
+
+    version, err := s.userRepo.Patch(ctx, id, patch, ifMatch)
+    if err != nil {
+        return nil, err
+    }
+
+    user.Version = version
+    if patch.Name != nil {
+        user.Name = *patch.Name
+    }
+    if patch.Email != nil {
+        user.Email = *patch.Email
+        user.EmailVerifiedAt = nil
+        s.reverifyEmail(ctx, user)
+    }
+
+    response := user.ToResponse()
+    return &response, nil
+}
+
+// diffUserPatch compares the user document before and after the patch and
+// collects the changed fields, rejecting changes to read-only fields.
+func diffUserPatch(original, patched []byte) (*models.UserPatch, error) {
+    var before, after map[string]interface{}
+    if err := json.Unmarshal(original, &before); err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(patched, &after); err != nil {
+        return nil, fmt.Errorf("%w: result must be an object", utils.ErrInvalidPatch)
+    }
+
+    for field, value := range after {
+        if _, ok := before[field]; !ok {
+            return nil, fmt.Errorf("%w: unknown field %q", utils.ErrInvalidPatch, field)
+        }
+        if !patchableUserFields[field] && !reflect.DeepEqual(before[field], value) {
+            return nil, fmt.Errorf("%w: field %q is read-only", utils.ErrInvalidPatch, field)
+        }
+    }
+
+    patch := &models.UserPatch{}
+    for field := range before {
+        value, ok := after[field]
+        if !ok {
+            return nil, fmt.Errorf("%w: field %q cannot be removed", utils.ErrInvalidPatch, field)
+        }
+        if !patchableUserFields[field] || reflect.DeepEqual(before[field], value) {
+            continue
+        }
+
+        str, ok := value.(string)
+        if !ok {
+            return nil, fmt.Errorf("%w: field %q must be a string", utils.ErrInvalidPatch, field)
+        }
+
+        switch field {
+        case "name":
+            patch.Name = &str
+        case "email":
+            patch.Email = &str
+        }
+    }
+
+    return patch, nil
+}
+
+func containsVersion(versions []int64, version int64) bool {
+    for _, v := range versions {
+        if v == version {
+            return true
+        }
+    }
+    return false
+}
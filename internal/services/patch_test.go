@@ -0,0 +1,89 @@
+package services
+
+import (
+    "encoding/json"
+    "errors"
+    "testing"
+
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+func TestDiffUserPatch(t *testing.T) {
+    user := &models.User{ID: 1, Name: "Alice", Email: "alice@example.com", Role: models.RoleUser}
+    original, err := json.Marshal(user.ToResponse())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    str := func(s string) *string { return &s }
+
+    tests := []struct {
+        name    string
+        merge   bool
+        patch   string
+        want    *models.UserPatch
+        wantErr bool
+    }{
+        {"replace name", false, `[{"op":"replace","path":"/name","value":"Alice Smith"}]`,
+            &models.UserPatch{Name: str("Alice Smith")}, false},
+        {"merge email", true, `{"email":"smith@example.com"}`,
+            &models.UserPatch{Email: str("smith@example.com")}, false},
+        {"unchanged values", true, `{"name":"Alice","role":"user"}`,
+            &models.UserPatch{}, false},
+        {"test and replace", false, `[{"op":"test","path":"/role","value":"user"},{"op":"replace","path":"/name","value":"Bob"}]`,
+            &models.UserPatch{Name: str("Bob")}, false},
+
+        {"remove a required field", false, `[{"op":"remove","path":"/email"}]`, nil, true},
+        {"merge null into a required field", true, `{"name":null}`, nil, true},
+        {"change a read-only field", false, `[{"op":"replace","path":"/role","value":"admin"}]`, nil, true},
+        {"add an unknown field", true, `{"password":"secret123"}`, nil, true},
+        {"non-string value", false, `[{"op":"replace","path":"/name","value":42}]`, nil, true},
+        {"replace the document with a scalar", true, `"alice"`, nil, true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var patched []byte
+            var err error
+            if tt.merge {
+                patched, err = utils.ApplyMergePatch(original, []byte(tt.patch))
+            } else {
+                patched, err = utils.ApplyJSONPatch(original, []byte(tt.patch))
+            }
+            if err != nil {
+                t.Fatalf("apply patch: %v", err)
+            }
+
+            got, err := diffUserPatch(original, patched)
+            if tt.wantErr {
+                if !errors.Is(err, utils.ErrInvalidPatch) {
+                    t.Errorf("err = %v, want ErrInvalidPatch", err)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("diffUserPatch: %v", err)
+            }
+
+            if !equalStringPtr(got.Name, tt.want.Name) || !equalStringPtr(got.Email, tt.want.Email) {
+                t.Errorf("patch = {Name:%v Email:%v}, want {Name:%v Email:%v}",
+                    derefString(got.Name), derefString(got.Email), derefString(tt.want.Name), derefString(tt.want.Email))
+            }
+        })
+    }
+}
+
+func equalStringPtr(a, b *string) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    return *a == *b
+}
+
+func derefString(s *string) string {
+    if s == nil {
+        return "<nil>"
+    }
+    return *s
+}
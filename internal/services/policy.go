@@ -0,0 +1,38 @@
+package services
+
+import (
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+var (
+    ErrForbidden = apperrors.Forbidden("forbidden")
+)
+
+type Policy struct {
+    RequireAdminMFA bool
+}
+
+func (p Policy) IsAdmin(actor models.Principal) bool {
+    return actor.IsAdmin() && (actor.MFA || !p.RequireAdminMFA)
+}
+
+func (p Policy) CanListUsers(actor models.Principal) bool {
+    return p.IsAdmin(actor)
+}
+
+func (p Policy) CanAccessUser(actor models.Principal, userID int64) bool {
+    return p.IsAdmin(actor) || actor.UserID == userID
+}
+
+func (p Policy) CanChangeRole(actor models.Principal) bool {
+    return p.IsAdmin(actor)
+}
+
+func (p Policy) CanRestoreUser(actor models.Principal) bool {
+    return p.IsAdmin(actor)
+}
+
+func (p Policy) CanListDeletedUsers(actor models.Principal) bool {
+    return p.IsAdmin(actor)
+}
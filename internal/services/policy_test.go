@@ -0,0 +1,50 @@
+package services
+
+import (
+    "testing"
+
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+)
+
+func TestPolicy(t *testing.T) {
+    user := models.Principal{UserID: 1, Role: models.RoleUser}
+    userWithMFA := models.Principal{UserID: 1, Role: models.RoleUser, MFA: true}
+    admin := models.Principal{UserID: 2, Role: models.RoleAdmin}
+    adminWithMFA := models.Principal{UserID: 2, Role: models.RoleAdmin, MFA: true}
+
+    type decisions struct {
+        admin, listUsers, accessSelf, accessOther, changeRole, restore, listDeleted bool
+    }
+
+    tests := []struct {
+        name            string
+        requireAdminMFA bool
+        actor           models.Principal
+        want            decisions
+    }{
+        {"user", false, user, decisions{accessSelf: true}},
+        {"user with MFA", true, userWithMFA, decisions{accessSelf: true}},
+        {"admin", false, admin, decisions{true, true, true, true, true, true, true}},
+        {"admin with MFA", false, adminWithMFA, decisions{true, true, true, true, true, true, true}},
+        {"admin without MFA when required", true, admin, decisions{accessSelf: true}},
+        {"admin with MFA when required", true, adminWithMFA, decisions{true, true, true, true, true, true, true}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            p := Policy{RequireAdminMFA: tt.requireAdminMFA}
+            got := decisions{
+                admin:       p.IsAdmin(tt.actor),
+                listUsers:   p.CanListUsers(tt.actor),
+                accessSelf:  p.CanAccessUser(tt.actor, tt.actor.UserID),
+                accessOther: p.CanAccessUser(tt.actor, tt.actor.UserID+100),
+                changeRole:  p.CanChangeRole(tt.actor),
+                restore:     p.CanRestoreUser(tt.actor),
+                listDeleted: p.CanListDeletedUsers(tt.actor),
+            }
+            if got != tt.want {
+                t.Errorf("decisions = %+v, want %+v", got, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+    "context"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+)
+
+// UserPurger permanently removes users that were soft-deleted longer than the retention period ago.
+type UserPurger struct {
+    userRepo  repository.UserRepository
+    retention time.Duration
+    interval  time.Duration
+}
+
+func NewUserPurger(userRepo repository.UserRepository, cfg config.UsersConfig) *UserPurger {
+    return &UserPurger{
+        userRepo:  userRepo,
+        retention: cfg.DeletedRetention,
+        interval:  cfg.PurgeInterval,
+    }
+}
+
+func (p *UserPurger) Run(ctx context.Context) {
+    if p.retention <= 0 || p.interval <= 0 {
+        logging.Info(ctx, "purge of deleted users is disabled")
+        return
+    }
+
+    ticker := time.NewTicker(p.interval)
+    defer ticker.Stop()
+
+    for {
+        p.Purge(ctx)
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (p *UserPurger) Purge(ctx context.Context) {
+    purged, err := p.userRepo.Purge(ctx, time.Now().Add(-p.retention))
+    if err != nil {
+        logging.Error(ctx, "purge of deleted users failed", "error", err)
+        return
+    }
+
+    if purged > 0 {
+        logging.Info(ctx, "deleted users purged", "count", purged)
+    }
+}
@@ -3,36 +3,76 @@ package services
 import (
     "context"
     "errors"
-    "log"
+    "time"
 
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/config"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/mailer"
     "github.com/MorozkoArt/go-crud-api/internal/models"
     "github.com/MorozkoArt/go-crud-api/internal/repository"
     "github.com/MorozkoArt/go-crud-api/internal/utils"
 )
 
+var ErrInvalidCredentials = apperrors.Unauthorized("invalid email or password")
+
+// dummyPasswordHash is checked against when no user has the email, so an
+// unknown email takes as long to reject as a wrong password. It must keep
+// the cost utils.HashPassword uses.
+const dummyPasswordHash = "$2a$10$nHJjOUxJcjx/hutg/A0t5ecZQqDc4LtNmx0vMJb/LRr9Eqv4mhIoq"
+
 type UserService interface {
-    Register(ctx context.Context, req *models.RegisterRequest) error
-    Login(ctx context.Context, req *models.LoginRequest) (*models.UserResponse, string, error)
-    GetAllUsers(ctx context.Context) ([]models.UserResponse, error)
-    GetUserByID(ctx context.Context, id int64) (*models.UserResponse, error)
-    UpdateUser(ctx context.Context, id int64, req *models.UpdateUserRequest) error
-    DeleteUser(ctx context.Context, id int64) error
+    Register(ctx context.Context, req *models.RegisterRequest) (*models.UserResponse, error)
+    Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResult, error)
+    LoginMFA(ctx context.Context, req *models.MFALoginRequest) (*models.LoginResult, error)
+    RefreshToken(ctx context.Context, req *models.RefreshRequest) (*models.TokenPair, error)
+    Logout(ctx context.Context, claims *utils.Claims, req *models.LogoutRequest) error
+    LogoutAll(ctx context.Context, userID int64) error
+    ListUsers(ctx context.Context, actor models.Principal, params *models.UserListParams) (*models.UserList, error)
+    GetUserByID(ctx context.Context, actor models.Principal, id int64) (*models.UserResponse, error)
+    UpdateUser(ctx context.Context, actor models.Principal, id int64, req *models.UpdateUserRequest) (*models.UserResponse, error)
+    PatchUser(ctx context.Context, actor models.Principal, id int64, req *models.PatchRequest) (*models.UserResponse, error)
+    DeleteUser(ctx context.Context, actor models.Principal, id int64, ifMatch []int64) error
+    RestoreUser(ctx context.Context, actor models.Principal, id int64) error
+    UpdateRole(ctx context.Context, actor models.Principal, id int64, req *models.UpdateRoleRequest) error
+    ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error
+    ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error
+    ChangePassword(ctx context.Context, actor models.Principal, id int64, req *models.ChangePasswordRequest) (*models.TokenPair, error)
+    EnrollTOTP(ctx context.Context, actor models.Principal, id int64) (*models.TOTPEnrollment, error)
+    ConfirmTOTP(ctx context.Context, actor models.Principal, id int64, req *models.ConfirmTOTPRequest) ([]string, error)
+    DisableTOTP(ctx context.Context, actor models.Principal, id int64, req *models.DisableTOTPRequest) error
+    VerifyEmail(ctx context.Context, token string) error
+    ResendVerification(ctx context.Context, req *models.ResendVerificationRequest) error
 }
 
 type userService struct {
-    userRepo    repository.UserRepository
-    authService AuthService
+    userRepo         repository.UserRepository
+    userTokenRepo    repository.UserTokenRepository
+    recoveryCodeRepo repository.RecoveryCodeRepository
+    txManager        repository.TxManager
+    authService      AuthService
+    loginLimiter     *LoginLimiter
+    mailer           mailer.Mailer
+    authConfig       config.AuthConfig
+    policy           Policy
 }
 
-func NewUserService(userRepo repository.UserRepository, authService AuthService) UserService {
+func NewUserService(userRepo repository.UserRepository, userTokenRepo repository.UserTokenRepository, recoveryCodeRepo repository.RecoveryCodeRepository, txManager repository.TxManager, authService AuthService, loginLimiter *LoginLimiter, mailer mailer.Mailer, authConfig config.AuthConfig) UserService {
     return &userService{
-        userRepo:    userRepo,
-        authService: authService,
+        userRepo:         userRepo,
+        userTokenRepo:    userTokenRepo,
+        recoveryCodeRepo: recoveryCodeRepo,
+        txManager:        txManager,
+        authService:      authService,
+        loginLimiter:     loginLimiter,
+        mailer:           mailer,
+        authConfig:       authConfig,
+        policy:           Policy{RequireAdminMFA: authConfig.RequireAdminMFA},
     }
 }
 
-func (s *userService) Register(ctx context.Context, req *models.RegisterRequest) error {
-    log.Printf("Service: Registering user: %s", req.Email)
+func (s *userService) Register(ctx context.Context, req *models.RegisterRequest) (*models.UserResponse, error) {
+    logging.Debug(ctx, "registering user", "email", req.Email)
     
     user := &models.User{
         Name:     req.Name,
@@ -40,74 +80,181 @@ func (s *userService) Register(ctx context.Context, req *models.RegisterRequest)
         Password: req.Password,
     }
     
-    return s.userRepo.Create(ctx, user)
+    if err := s.userRepo.Create(ctx, user); err != nil {
+        return nil, err
+    }
+
+    if err := s.sendVerificationEmail(ctx, user); err != nil {
+        logging.Warn(ctx, "verification email not sent", "email", req.Email)
+    }
+
+    response := user.ToResponse()
+    return &response, nil
 }
 
-func (s *userService) Login(ctx context.Context, req *models.LoginRequest) (*models.UserResponse, string, error) {
-    log.Printf("Service: Login attempt for: %s", req.Email)
+func (s *userService) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResult, error) {
+    logging.Debug(ctx, "login attempt", "email", req.Email)
+
+    // The attempt is counted before the password is checked and stays a
+    // failure unless it is released or succeeds below.
+    if err := s.loginLimiter.Reserve(ctx, req.Email, req.ClientIP); err != nil {
+        logging.Warn(ctx, "login throttled", "email", req.Email)
+        return nil, err
+    }
     
     user, err := s.userRepo.GetByEmail(ctx, req.Email)
     if err != nil {
-        log.Printf("Service: Login failed - user not found: %s", req.Email)
-        return nil, "", errors.New("invalid credentials")
+        utils.CheckPasswordHash(req.Password, dummyPasswordHash)
+        logging.Info(ctx, "login failed", "email", req.Email, "reason", "user not found")
+        return nil, ErrInvalidCredentials
     }
 
     if !utils.CheckPasswordHash(req.Password, user.Password) {
-        log.Printf("Service: Login failed - invalid password for: %s", req.Email)
-        return nil, "", errors.New("invalid credentials")
+        logging.Info(ctx, "login failed", "email", req.Email, "reason", "invalid password")
+        return nil, ErrInvalidCredentials
     }
 
-    token, err := s.authService.GenerateToken(user.ID, user.Email)
+    if s.authConfig.RequireEmailVerification && user.EmailVerifiedAt == nil {
+        logging.Info(ctx, "login failed", "email", req.Email, "reason", "email not verified")
+        if err := s.loginLimiter.Release(ctx, req.Email, req.ClientIP); err != nil {
+            return nil, err
+        }
+        return nil, ErrEmailNotVerified
+    }
+
+    if user.TOTPEnabledAt != nil {
+        // The password was right, but the failures are not reset until the
+        // second factor is verified too.
+        if err := s.loginLimiter.Release(ctx, req.Email, req.ClientIP); err != nil {
+            return nil, err
+        }
+
+        mfaToken, err := s.authService.IssueMFAChallenge(ctx, user)
+        if err != nil {
+            return nil, err
+        }
+
+        logging.Info(ctx, "MFA challenge issued", "email", req.Email)
+        return &models.LoginResult{MFAToken: mfaToken}, nil
+    }
+
+    if err := s.loginLimiter.Success(ctx, req.Email, req.ClientIP); err != nil {
+        return nil, err
+    }
+
+    tokens, err := s.authService.IssueTokens(ctx, user, "", false)
     if err != nil {
-        log.Printf("Service: Token generation failed: %v", err)
-        return nil, "", err
+        logging.Error(ctx, "token generation failed", "error", err)
+        return nil, err
     }
 
-    log.Printf("Service: Login successful for: %s", req.Email)
-    return &models.UserResponse{
-        ID:    user.ID,
-        Name:  user.Name,
-        Email: user.Email,
-    }, token, nil
+    s.recordLogin(ctx, user)
+
+    logging.Info(ctx, "login successful", "email", req.Email)
+    response := user.ToResponse()
+    return &models.LoginResult{User: &response, Tokens: tokens}, nil
 }
 
-func (s *userService) GetAllUsers(ctx context.Context) ([]models.UserResponse, error) {
-    log.Printf("Service: Fetching all users")
+func (s *userService) RefreshToken(ctx context.Context, req *models.RefreshRequest) (*models.TokenPair, error) {
+    logging.Debug(ctx, "refreshing token")
+
+    consumed, err := s.authService.ConsumeRefreshToken(ctx, req.RefreshToken)
+    if err != nil {
+        logging.Info(ctx, "token refresh failed", "error", err)
+        return nil, err
+    }
+
+    user, err := s.userRepo.GetByID(ctx, consumed.UserID)
+    if err != nil {
+        return nil, err
+    }
+
+    return s.authService.IssueTokens(ctx, user, consumed.FamilyID, consumed.MFA)
+}
+
+func (s *userService) Logout(ctx context.Context, claims *utils.Claims, req *models.LogoutRequest) error {
+    logging.Debug(ctx, "logging out", "user_id", claims.UserID)
+
+    if req.RefreshToken != "" {
+        err := s.authService.RevokeRefreshToken(ctx, claims.UserID, req.RefreshToken)
+        if err != nil && !errors.Is(err, ErrInvalidRefreshToken) {
+            return err
+        }
+    }
+
+    return s.authService.RevokeToken(ctx, claims)
+}
+
+func (s *userService) LogoutAll(ctx context.Context, userID int64) error {
+    logging.Debug(ctx, "logging out all sessions", "user_id", userID)
+    return s.authService.RevokeAllSessions(ctx, userID)
+}
+
+func (s *userService) ListUsers(ctx context.Context, actor models.Principal, params *models.UserListParams) (*models.UserList, error) {
+    logging.Debug(ctx, "listing users")
+
+    if !s.policy.CanListUsers(actor) {
+        return nil, ErrForbidden
+    }
+
+    if params.IncludeDeleted && !s.policy.CanListDeletedUsers(actor) {
+        return nil, ErrForbidden
+    }
+
+    if params.Limit <= 0 {
+        params.Limit = models.DefaultListLimit
+    }
+    if params.Limit > models.MaxListLimit {
+        params.Limit = models.MaxListLimit
+    }
+    if params.Offset < 0 {
+        params.Offset = 0
+    }
+    if params.Sort == "" {
+        params.Sort = "id"
+    }
     
-    users, err := s.userRepo.GetAll(ctx)
+    page, err := s.userRepo.List(ctx, params)
     if err != nil {
         return nil, err
     }
 
-    var response []models.UserResponse
-    for _, user := range users {
-        response = append(response, models.UserResponse{
-            ID:    user.ID,
-            Name:  user.Name,
-            Email: user.Email,
-        })
+    response := make([]models.UserResponse, 0, len(page.Users))
+    for _, user := range page.Users {
+        response = append(response, user.ToResponse())
     }
 
-    return response, nil
+    return &models.UserList{
+        Users:      response,
+        Total:      page.Total,
+        Limit:      params.Limit,
+        Offset:     params.Offset,
+        NextCursor: page.NextCursor,
+    }, nil
 }
 
-func (s *userService) GetUserByID(ctx context.Context, id int64) (*models.UserResponse, error) {
-    log.Printf("Service: Fetching user by ID: %d", id)
+func (s *userService) GetUserByID(ctx context.Context, actor models.Principal, id int64) (*models.UserResponse, error) {
+    logging.Debug(ctx, "fetching user", "user_id", id)
+
+    if !s.policy.CanAccessUser(actor, id) {
+        return nil, ErrForbidden
+    }
     
     user, err := s.userRepo.GetByID(ctx, id)
     if err != nil {
         return nil, err
     }
 
-    return &models.UserResponse{
-        ID:    user.ID,
-        Name:  user.Name,
-        Email: user.Email,
-    }, nil
+    response := user.ToResponse()
+    return &response, nil
 }
 
-func (s *userService) UpdateUser(ctx context.Context, id int64, req *models.UpdateUserRequest) error {
-    log.Printf("Service: Updating user ID: %d", id)
+func (s *userService) UpdateUser(ctx context.Context, actor models.Principal, id int64, req *models.UpdateUserRequest) (*models.UserResponse, error) {
+    logging.Debug(ctx, "updating user", "user_id", id)
+
+    if !s.policy.CanAccessUser(actor, id) {
+        return nil, ErrForbidden
+    }
     
     user := &models.User{
         ID:    id,
@@ -115,10 +262,81 @@ func (s *userService) UpdateUser(ctx context.Context, id int64, req *models.Upda
         Email: req.Email,
     }
     
-    return s.userRepo.Update(ctx, user)
+    var current, updated *models.User
+    err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+        var err error
+        current, err = s.userRepo.GetByID(ctx, id)
+        if err != nil {
+            return err
+        }
+
+        if err := s.userRepo.Update(ctx, user, req.IfMatch); err != nil {
+            return err
+        }
+
+        updated, err = s.userRepo.GetByID(ctx, id)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    if updated.Email != current.Email {
+        s.reverifyEmail(ctx, updated)
+    }
+
+    response := updated.ToResponse()
+    return &response, nil
 }
 
-func (s *userService) DeleteUser(ctx context.Context, id int64) error {
-    log.Printf("Service: Deleting user ID: %d", id)
-    return s.userRepo.Delete(ctx, id)
-}
\ No newline at end of file
+func (s *userService) DeleteUser(ctx context.Context, actor models.Principal, id int64, ifMatch []int64) error {
+    logging.Debug(ctx, "deleting user", "user_id", id)
+
+    if !s.policy.CanAccessUser(actor, id) {
+        return ErrForbidden
+    }
+    
+    return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+        if err := s.userRepo.Delete(ctx, id, ifMatch); err != nil {
+            return err
+        }
+
+        return s.authService.RevokeAllSessions(ctx, id)
+    })
+}
+
+func (s *userService) RestoreUser(ctx context.Context, actor models.Principal, id int64) error {
+    logging.Debug(ctx, "restoring user", "user_id", id)
+
+    if !s.policy.CanRestoreUser(actor) {
+        return ErrForbidden
+    }
+
+    return s.userRepo.Restore(ctx, id)
+}
+
+func (s *userService) UpdateRole(ctx context.Context, actor models.Principal, id int64, req *models.UpdateRoleRequest) error {
+    logging.Debug(ctx, "updating user role", "user_id", id, "role", req.Role)
+
+    if !s.policy.CanChangeRole(actor) {
+        return ErrForbidden
+    }
+
+    return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+        if err := s.userRepo.UpdateRole(ctx, id, req.Role); err != nil {
+            return err
+        }
+
+        return s.authService.RevokeAllSessions(ctx, id)
+    })
+}
+
+func (s *userService) recordLogin(ctx context.Context, user *models.User) {
+    if err := s.userRepo.RecordLogin(ctx, user.ID); err != nil {
+        logging.Warn(ctx, "last login not recorded", "user_id", user.ID)
+        return
+    }
+
+    now := time.Now()
+    user.LastLoginAt = &now
+}
@@ -0,0 +1,17 @@
+package services
+
+import (
+    "testing"
+
+    "golang.org/x/crypto/bcrypt"
+)
+
+func TestDummyPasswordHashCost(t *testing.T) {
+    cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+    if err != nil {
+        t.Fatalf("dummyPasswordHash is not a bcrypt hash: %v", err)
+    }
+    if cost != bcrypt.DefaultCost {
+        t.Errorf("cost = %d, want %d like utils.HashPassword", cost, bcrypt.DefaultCost)
+    }
+}
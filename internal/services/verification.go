@@ -0,0 +1,116 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/url"
+    "time"
+
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+    "github.com/MorozkoArt/go-crud-api/internal/logging"
+    "github.com/MorozkoArt/go-crud-api/internal/mailer"
+    "github.com/MorozkoArt/go-crud-api/internal/models"
+    "github.com/MorozkoArt/go-crud-api/internal/repository"
+    "github.com/MorozkoArt/go-crud-api/internal/utils"
+)
+
+var (
+    ErrInvalidVerificationToken = apperrors.Validation("invalid or expired verification token")
+    ErrEmailNotVerified         = apperrors.Forbidden("email address is not verified")
+    ErrVerificationThrottled    = apperrors.RateLimited("verification email was sent recently, try again later")
+)
+
+func (s *userService) VerifyEmail(ctx context.Context, token string) error {
+    logging.Debug(ctx, "verifying email")
+
+    return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+        consumed, err := s.userTokenRepo.Consume(ctx, utils.HashToken(token), models.TokenPurposeEmailVerification)
+        if errors.Is(err, repository.ErrUserTokenNotFound) {
+            return ErrInvalidVerificationToken
+        }
+        if err != nil {
+            return err
+        }
+
+        if err := s.userRepo.MarkEmailVerified(ctx, consumed.UserID); err != nil {
+            return err
+        }
+
+        logging.Info(ctx, "email verified", "user_id", consumed.UserID)
+        return nil
+    })
+}
+
+func (s *userService) ResendVerification(ctx context.Context, req *models.ResendVerificationRequest) error {
+    logging.Debug(ctx, "verification email resend requested", "email", req.Email)
+
+    user, err := s.userRepo.GetByEmail(ctx, req.Email)
+    if errors.Is(err, repository.ErrUserNotFound) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+
+    if user.EmailVerifiedAt != nil {
+        return nil
+    }
+
+    lastSent, err := s.userTokenRepo.LatestCreatedAt(ctx, user.ID, models.TokenPurposeEmailVerification)
+    if err != nil {
+        return err
+    }
+
+    if wait := s.authConfig.VerificationResendInterval - time.Since(lastSent); wait > 0 {
+        return &apperrors.RetryAfterError{Err: ErrVerificationThrottled, RetryAfter: wait}
+    }
+
+    return s.sendVerificationEmail(ctx, user)
+}
+
+// reverifyEmail sends a verification email to a changed address. The
+// repository has already cleared the verification of the old one.
+func (s *userService) reverifyEmail(ctx context.Context, user *models.User) {
+    logging.Info(ctx, "email changed, verification required", "user_id", user.ID)
+
+    if err := s.sendVerificationEmail(ctx, user); err != nil {
+        logging.Warn(ctx, "verification email not sent", "email", user.Email)
+    }
+}
+
+func (s *userService) sendVerificationEmail(ctx context.Context, user *models.User) error {
+    if err := s.userTokenRepo.InvalidateAll(ctx, user.ID, models.TokenPurposeEmailVerification); err != nil {
+        return err
+    }
+
+    token, err := utils.GenerateRandomToken(32)
+    if err != nil {
+        return err
+    }
+
+    err = s.userTokenRepo.Create(ctx, &models.UserToken{
+        UserID:    user.ID,
+        Purpose:   models.TokenPurposeEmailVerification,
+        TokenHash: utils.HashToken(token),
+        ExpiresAt: time.Now().Add(s.authConfig.EmailVerificationExpiry),
+    })
+    if err != nil {
+        return err
+    }
+
+    link := s.authConfig.EmailVerificationURL + "?token=" + url.QueryEscape(token)
+
+    err = s.mailer.Send(ctx, mailer.Message{
+        To:      user.Email,
+        Subject: "Confirm your email address",
+        Body: fmt.Sprintf(
+            "Hello, %s!\n\nTo confirm your email address, open the link below:\n%s\n\nThe link expires in %s.\n",
+            user.Name, link, s.authConfig.EmailVerificationExpiry),
+    })
+    if err != nil {
+        logging.Error(ctx, "failed to send verification email", "error", err)
+    }
+
+    return err
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
     "errors"
+    "fmt"
+    "sort"
     "time"
 
     "github.com/golang-jwt/jwt/v5"
@@ -9,55 +11,154 @@ import (
 
 var (
     ErrInvalidToken = errors.New("invalid token")
+    ErrUnknownKey   = errors.New("unknown signing key")
+)
+
+const (
+    TokenUseAccess       = "access"
+    TokenUseMFAChallenge = "mfa_challenge"
 )
 
 type Claims struct {
-    UserID int64  `json:"user_id"`
-    Email  string `json:"email"`
+    UserID     int64  `json:"user_id"`
+    Email      string `json:"email"`
+    Role       string `json:"role"`
+    MFA        bool   `json:"mfa,omitempty"`
+    TokenUse   string `json:"token_use,omitempty"`
+    Generation int64  `json:"gen,omitempty"`
     jwt.RegisteredClaims
 }
 
 type JWTService struct {
-    secretKey []byte
-    expiry    time.Duration
+    signingKey *SigningKey
+    keys       map[string]*SigningKey
+    methods    []string
+    expiry     time.Duration
 }
 
 func NewJWTService(secretKey string, expiry time.Duration) *JWTService {
+    key := &SigningKey{
+        Method:     jwt.SigningMethodHS256,
+        PrivateKey: []byte(secretKey),
+        PublicKey:  []byte(secretKey),
+    }
+
     return &JWTService{
-        secretKey: []byte(secretKey),
-        expiry:    expiry,
+        signingKey: key,
+        keys:       map[string]*SigningKey{"": key},
+        methods:    []string{key.Method.Alg()},
+        expiry:     expiry,
     }
 }
 
-func (j *JWTService) GenerateToken(userID int64, email string) (string, error) {
-    expirationTime := time.Now().Add(j.expiry)
-    
-    claims := &Claims{
-        UserID: userID,
-        Email:  email,
-        RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(expirationTime),
-            IssuedAt:  jwt.NewNumericDate(time.Now()),
-            Subject:   email,
-        },
+func NewJWTServiceWithKeys(keys []*SigningKey, activeKID string, expiry time.Duration) (*JWTService, error) {
+    j := &JWTService{
+        keys:   make(map[string]*SigningKey, len(keys)),
+        expiry: expiry,
+    }
+
+    seen := make(map[string]bool)
+    for _, key := range keys {
+        if _, exists := j.keys[key.KID]; exists {
+            return nil, fmt.Errorf("duplicate signing key id %q", key.KID)
+        }
+        j.keys[key.KID] = key
+
+        if !seen[key.Method.Alg()] {
+            seen[key.Method.Alg()] = true
+            j.methods = append(j.methods, key.Method.Alg())
+        }
+    }
+
+    active, ok := j.keys[activeKID]
+    if !ok {
+        return nil, fmt.Errorf("active signing key %q is not configured", activeKID)
     }
-    
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString(j.secretKey)
+    if active.PrivateKey == nil {
+        return nil, fmt.Errorf("active signing key %q has no private key", activeKID)
+    }
+    j.signingKey = active
+
+    return j, nil
+}
+
+func (j *JWTService) GenerateToken(userID int64, email, role string, mfa bool, generation int64) (string, error) {
+    return j.sign(&Claims{
+        UserID:     userID,
+        Email:      email,
+        Role:       role,
+        MFA:        mfa,
+        TokenUse:   TokenUseAccess,
+        Generation: generation,
+    }, j.expiry)
+}
+
+func (j *JWTService) GenerateMFAChallenge(userID int64, email string, generation int64, expiry time.Duration) (string, error) {
+    return j.sign(&Claims{
+        UserID:     userID,
+        Email:      email,
+        TokenUse:   TokenUseMFAChallenge,
+        Generation: generation,
+    }, expiry)
+}
+
+func (j *JWTService) sign(claims *Claims, expiry time.Duration) (string, error) {
+    jti, err := GenerateRandomToken(16)
+    if err != nil {
+        return "", err
+    }
+
+    now := time.Now()
+    claims.RegisteredClaims = jwt.RegisteredClaims{
+        ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+        IssuedAt:  jwt.NewNumericDate(now),
+        Subject:   claims.Email,
+        ID:        jti,
+    }
+
+    token := jwt.NewWithClaims(j.signingKey.Method, claims)
+    if j.signingKey.KID != "" {
+        token.Header["kid"] = j.signingKey.KID
+    }
+    return token.SignedString(j.signingKey.PrivateKey)
 }
 
 func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
     token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-        return j.secretKey, nil
-    })
-    
+        kid, _ := token.Header["kid"].(string)
+
+        key, ok := j.keys[kid]
+        if !ok {
+            return nil, ErrUnknownKey
+        }
+        if token.Method.Alg() != key.Method.Alg() {
+            return nil, ErrInvalidToken
+        }
+
+        return key.PublicKey, nil
+    }, jwt.WithValidMethods(j.methods))
+
     if err != nil {
         return nil, err
     }
-    
+
     if claims, ok := token.Claims.(*Claims); ok && token.Valid {
         return claims, nil
     }
-    
+
     return nil, ErrInvalidToken
-}
\ No newline at end of file
+}
+
+func (j *JWTService) JWKS() JWKS {
+    jwks := JWKS{Keys: []JWK{}}
+    for _, key := range j.keys {
+        if jwk, ok := key.JWK(); ok {
+            jwks.Keys = append(jwks.Keys, jwk)
+        }
+    }
+
+    sort.Slice(jwks.Keys, func(a, b int) bool {
+        return jwks.Keys[a].KID < jwks.Keys[b].KID
+    })
+    return jwks
+}
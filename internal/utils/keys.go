@@ -0,0 +1,178 @@
+package utils
+
+import (
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/ed25519"
+    "crypto/elliptic"
+    "crypto/rsa"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "math/big"
+    "os"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+var (
+    ErrUnsupportedKey = errors.New("unsupported key type")
+)
+
+type SigningKey struct {
+    KID        string
+    Method     jwt.SigningMethod
+    PrivateKey interface{}
+    PublicKey  interface{}
+}
+
+type JWK struct {
+    Kty string `json:"kty"`
+    KID string `json:"kid,omitempty"`
+    Use string `json:"use"`
+    Alg string `json:"alg"`
+    N   string `json:"n,omitempty"`
+    E   string `json:"e,omitempty"`
+    Crv string `json:"crv,omitempty"`
+    X   string `json:"x,omitempty"`
+    Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+    Keys []JWK `json:"keys"`
+}
+
+func SigningMethodFor(algorithm string) (jwt.SigningMethod, error) {
+    switch algorithm {
+    case "RS256":
+        return jwt.SigningMethodRS256, nil
+    case "ES256":
+        return jwt.SigningMethodES256, nil
+    case "EdDSA":
+        return jwt.SigningMethodEdDSA, nil
+    }
+    return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+}
+
+func LoadSigningKey(kid, algorithm, privateKeyFile, publicKeyFile string) (*SigningKey, error) {
+    method, err := SigningMethodFor(algorithm)
+    if err != nil {
+        return nil, err
+    }
+
+    key := &SigningKey{KID: kid, Method: method}
+
+    if privateKeyFile != "" {
+        block, err := readPEM(privateKeyFile)
+        if err != nil {
+            return nil, err
+        }
+        key.PrivateKey, err = parsePrivateKey(block)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", privateKeyFile, err)
+        }
+        key.PublicKey = key.PrivateKey.(crypto.Signer).Public()
+    }
+
+    if publicKeyFile != "" {
+        block, err := readPEM(publicKeyFile)
+        if err != nil {
+            return nil, err
+        }
+        key.PublicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", publicKeyFile, err)
+        }
+    }
+
+    if key.PublicKey == nil {
+        return nil, fmt.Errorf("signing key %q has neither a private nor a public key file", kid)
+    }
+
+    if !keyMatchesMethod(key.PublicKey, method) {
+        return nil, fmt.Errorf("signing key %q cannot be used with %s", kid, algorithm)
+    }
+
+    return key, nil
+}
+
+func (k *SigningKey) JWK() (JWK, bool) {
+    jwk := JWK{
+        KID: k.KID,
+        Use: "sig",
+        Alg: k.Method.Alg(),
+    }
+
+    switch pub := k.PublicKey.(type) {
+    case *rsa.PublicKey:
+        jwk.Kty = "RSA"
+        jwk.N = encodeBase64URL(pub.N.Bytes())
+        jwk.E = encodeBase64URL(big.NewInt(int64(pub.E)).Bytes())
+    case *ecdsa.PublicKey:
+        size := (pub.Curve.Params().BitSize + 7) / 8
+        jwk.Kty = "EC"
+        jwk.Crv = pub.Curve.Params().Name
+        jwk.X = encodeBase64URL(pub.X.FillBytes(make([]byte, size)))
+        jwk.Y = encodeBase64URL(pub.Y.FillBytes(make([]byte, size)))
+    case ed25519.PublicKey:
+        jwk.Kty = "OKP"
+        jwk.Crv = "Ed25519"
+        jwk.X = encodeBase64URL(pub)
+    default:
+        return JWK{}, false
+    }
+
+    return jwk, true
+}
+
+func readPEM(path string) (*pem.Block, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    block, _ := pem.Decode(data)
+    if block == nil {
+        return nil, fmt.Errorf("%s: no PEM data found", path)
+    }
+
+    return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (interface{}, error) {
+    switch block.Type {
+    case "RSA PRIVATE KEY":
+        return x509.ParsePKCS1PrivateKey(block.Bytes)
+    case "EC PRIVATE KEY":
+        return x509.ParseECPrivateKey(block.Bytes)
+    }
+
+    key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+    if err != nil {
+        return nil, err
+    }
+
+    switch key.(type) {
+    case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+        return key, nil
+    }
+    return nil, ErrUnsupportedKey
+}
+
+func keyMatchesMethod(publicKey interface{}, method jwt.SigningMethod) bool {
+    switch pub := publicKey.(type) {
+    case *rsa.PublicKey:
+        return method == jwt.SigningMethodRS256
+    case *ecdsa.PublicKey:
+        return method == jwt.SigningMethodES256 && pub.Curve == elliptic.P256()
+    case ed25519.PublicKey:
+        return method == jwt.SigningMethodEdDSA
+    }
+    return false
+}
+
+func encodeBase64URL(data []byte) string {
+    return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,359 @@
+package utils
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "strconv"
+    "strings"
+
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+)
+
+var (
+    ErrInvalidPatch    = apperrors.Validation("invalid patch")
+    ErrPatchTestFailed = apperrors.Conflict("patch test failed")
+)
+
+// pointerUnescaper decodes a JSON pointer reference token; ~1 goes first so
+// that "~01" becomes "~1" and not "/" (RFC 6901, section 4).
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7386) to doc.
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+    var target, p interface{}
+    if err := json.Unmarshal(doc, &target); err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(patch, &p); err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+    }
+
+    return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+    p, ok := patch.(map[string]interface{})
+    if !ok {
+        return patch
+    }
+
+    t, ok := target.(map[string]interface{})
+    if !ok {
+        t = map[string]interface{}{}
+    }
+
+    for key, value := range p {
+        if value == nil {
+            delete(t, key)
+        } else {
+            t[key] = mergePatch(t[key], value)
+        }
+    }
+
+    return t
+}
+
+type patchOperation struct {
+    Op    string          `json:"op"`
+    Path  *string         `json:"path"`
+    From  *string         `json:"from"`
+    Value json.RawMessage `json:"value"`
+}
+
+// ApplyJSONPatch applies a JSON Patch (RFC 6902) to doc. Operations are
+// applied in order and the whole patch fails if any of them fails.
+func ApplyJSONPatch(doc, patch []byte) ([]byte, error) {
+    var target interface{}
+    if err := json.Unmarshal(doc, &target); err != nil {
+        return nil, err
+    }
+
+    var ops []patchOperation
+    if err := json.Unmarshal(patch, &ops); err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+    }
+
+    for i, op := range ops {
+        var err error
+        if target, err = applyOperation(target, op); err != nil {
+            if errors.Is(err, ErrPatchTestFailed) {
+                return nil, err
+            }
+            return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+        }
+    }
+
+    return json.Marshal(target)
+}
+
+func applyOperation(doc interface{}, op patchOperation) (interface{}, error) {
+    if op.Path == nil {
+        return nil, errors.New("missing path")
+    }
+
+    path, err := parsePointer(*op.Path)
+    if err != nil {
+        return nil, err
+    }
+
+    switch op.Op {
+    case "add", "replace", "test":
+        if op.Value == nil {
+            return nil, fmt.Errorf("missing value for %s", op.Op)
+        }
+
+        var value interface{}
+        if err := json.Unmarshal(op.Value, &value); err != nil {
+            return nil, err
+        }
+
+        switch op.Op {
+        case "add":
+            return addValue(doc, path, value)
+        case "replace":
+            return replaceValue(doc, path, value)
+        }
+
+        current, err := getValue(doc, path)
+        if err != nil {
+            return nil, err
+        }
+        if !reflect.DeepEqual(current, value) {
+            return nil, fmt.Errorf("%w: %s", ErrPatchTestFailed, *op.Path)
+        }
+        return doc, nil
+    case "remove":
+        doc, _, err := removeValue(doc, path)
+        return doc, err
+    case "move", "copy":
+        if op.From == nil {
+            return nil, fmt.Errorf("missing from for %s", op.Op)
+        }
+
+        from, err := parsePointer(*op.From)
+        if err != nil {
+            return nil, err
+        }
+
+        if op.Op == "copy" {
+            value, err := getValue(doc, from)
+            if err != nil {
+                return nil, err
+            }
+            copied, err := deepCopy(value)
+            if err != nil {
+                return nil, err
+            }
+            return addValue(doc, path, copied)
+        }
+
+        if len(from) < len(path) && isPrefix(from, path) {
+            return nil, errors.New("cannot move a value into one of its children")
+        }
+
+        doc, value, err := removeValue(doc, from)
+        if err != nil {
+            return nil, err
+        }
+        return addValue(doc, path, value)
+    }
+
+    return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+func parsePointer(pointer string) ([]string, error) {
+    if pointer == "" {
+        return nil, nil
+    }
+    if !strings.HasPrefix(pointer, "/") {
+        return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+    }
+
+    tokens := strings.Split(pointer[1:], "/")
+    for i, token := range tokens {
+        tokens[i] = pointerUnescaper.Replace(token)
+    }
+    return tokens, nil
+}
+
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+    if allowEnd && token == "-" {
+        return length, nil
+    }
+    if token == "" || (len(token) > 1 && token[0] == '0') {
+        return 0, fmt.Errorf("invalid array index %q", token)
+    }
+
+    index, err := strconv.Atoi(token)
+    if err != nil || index < 0 {
+        return 0, fmt.Errorf("invalid array index %q", token)
+    }
+
+    max := length - 1
+    if allowEnd {
+        max = length
+    }
+    if index > max {
+        return 0, fmt.Errorf("array index %d out of range", index)
+    }
+    return index, nil
+}
+
+func getValue(doc interface{}, path []string) (interface{}, error) {
+    for _, token := range path {
+        switch node := doc.(type) {
+        case map[string]interface{}:
+            value, ok := node[token]
+            if !ok {
+                return nil, fmt.Errorf("path member %q not found", token)
+            }
+            doc = value
+        case []interface{}:
+            index, err := arrayIndex(token, len(node), false)
+            if err != nil {
+                return nil, err
+            }
+            doc = node[index]
+        default:
+            return nil, fmt.Errorf("path member %q not found", token)
+        }
+    }
+    return doc, nil
+}
+
+// updateParent walks to the container holding the last path token and lets
+// fn replace it, so that slices grown or shrunk by fn are stored back.
+func updateParent(doc interface{}, path []string, fn func(container interface{}, key string) (interface{}, error)) (interface{}, error) {
+    if len(path) == 1 {
+        return fn(doc, path[0])
+    }
+
+    switch node := doc.(type) {
+    case map[string]interface{}:
+        child, ok := node[path[0]]
+        if !ok {
+            return nil, fmt.Errorf("path member %q not found", path[0])
+        }
+        updated, err := updateParent(child, path[1:], fn)
+        if err != nil {
+            return nil, err
+        }
+        node[path[0]] = updated
+        return node, nil
+    case []interface{}:
+        index, err := arrayIndex(path[0], len(node), false)
+        if err != nil {
+            return nil, err
+        }
+        updated, err := updateParent(node[index], path[1:], fn)
+        if err != nil {
+            return nil, err
+        }
+        node[index] = updated
+        return node, nil
+    }
+
+    return nil, fmt.Errorf("path member %q not found", path[0])
+}
+
+func addValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+    if len(path) == 0 {
+        return value, nil
+    }
+
+    return updateParent(doc, path, func(container interface{}, key string) (interface{}, error) {
+        switch node := container.(type) {
+        case map[string]interface{}:
+            node[key] = value
+            return node, nil
+        case []interface{}:
+            index, err := arrayIndex(key, len(node), true)
+            if err != nil {
+                return nil, err
+            }
+            node = append(node, nil)
+            copy(node[index+1:], node[index:])
+            node[index] = value
+            return node, nil
+        }
+        return nil, fmt.Errorf("cannot add member %q to a scalar value", key)
+    })
+}
+
+func replaceValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+    if len(path) == 0 {
+        return value, nil
+    }
+
+    return updateParent(doc, path, func(container interface{}, key string) (interface{}, error) {
+        switch node := container.(type) {
+        case map[string]interface{}:
+            if _, ok := node[key]; !ok {
+                return nil, fmt.Errorf("path member %q not found", key)
+            }
+            node[key] = value
+            return node, nil
+        case []interface{}:
+            index, err := arrayIndex(key, len(node), false)
+            if err != nil {
+                return nil, err
+            }
+            node[index] = value
+            return node, nil
+        }
+        return nil, fmt.Errorf("path member %q not found", key)
+    })
+}
+
+func removeValue(doc interface{}, path []string) (interface{}, interface{}, error) {
+    if len(path) == 0 {
+        return nil, nil, errors.New("cannot remove the whole document")
+    }
+
+    var removed interface{}
+    doc, err := updateParent(doc, path, func(container interface{}, key string) (interface{}, error) {
+        switch node := container.(type) {
+        case map[string]interface{}:
+            value, ok := node[key]
+            if !ok {
+                return nil, fmt.Errorf("path member %q not found", key)
+            }
+            removed = value
+            delete(node, key)
+            return node, nil
+        case []interface{}:
+            index, err := arrayIndex(key, len(node), false)
+            if err != nil {
+                return nil, err
+            }
+            removed = node[index]
+            return append(node[:index], node[index+1:]...), nil
+        }
+        return nil, fmt.Errorf("path member %q not found", key)
+    })
+    return doc, removed, err
+}
+
+func isPrefix(prefix, path []string) bool {
+    for i := range prefix {
+        if prefix[i] != path[i] {
+            return false
+        }
+    }
+    return true
+}
+
+func deepCopy(value interface{}) (interface{}, error) {
+    data, err := json.Marshal(value)
+    if err != nil {
+        return nil, err
+    }
+
+    var copied interface{}
+    if err := json.Unmarshal(data, &copied); err != nil {
+        return nil, err
+    }
+    return copied, nil
+}
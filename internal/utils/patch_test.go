@@ -0,0 +1,199 @@
+package utils
+
+import (
+    "encoding/json"
+    "errors"
+    "reflect"
+    "testing"
+)
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+    t.Helper()
+
+    var g, w interface{}
+    if err := json.Unmarshal(got, &g); err != nil {
+        t.Fatalf("invalid result %s: %v", got, err)
+    }
+    if err := json.Unmarshal([]byte(want), &w); err != nil {
+        t.Fatalf("invalid expected document %s: %v", want, err)
+    }
+    if !reflect.DeepEqual(g, w) {
+        t.Errorf("result = %s, want %s", got, want)
+    }
+}
+
+// The cases are the examples from RFC 7386, Appendix A.
+func TestApplyMergePatch(t *testing.T) {
+    tests := []struct {
+        doc, patch, want string
+    }{
+        {`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+        {`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+        {`{"a":"b"}`, `{"a":null}`, `{}`},
+        {`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+        {`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+        {`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+        {`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+        {`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+        {`["a","b"]`, `["c","d"]`, `["c","d"]`},
+        {`{"a":"b"}`, `["c"]`, `["c"]`},
+        {`{"a":"foo"}`, `null`, `null`},
+        {`{"a":"foo"}`, `"bar"`, `"bar"`},
+        {`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+        {`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+        {`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.patch, func(t *testing.T) {
+            got, err := ApplyMergePatch([]byte(tt.doc), []byte(tt.patch))
+            if err != nil {
+                t.Fatalf("ApplyMergePatch: %v", err)
+            }
+            assertJSONEqual(t, got, tt.want)
+        })
+    }
+}
+
+func TestApplyMergePatchRejectsInvalidJSON(t *testing.T) {
+    _, err := ApplyMergePatch([]byte(`{"a":"b"}`), []byte(`{"a":`))
+    if !errors.Is(err, ErrInvalidPatch) {
+        t.Errorf("err = %v, want ErrInvalidPatch", err)
+    }
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+    tests := []struct {
+        name  string
+        doc   string
+        patch string
+        want  string
+    }{
+        // RFC 6902, Appendix A.
+        {"A.1 add object member", `{"foo":"bar"}`,
+            `[{"op":"add","path":"/baz","value":"qux"}]`,
+            `{"baz":"qux","foo":"bar"}`},
+        {"A.2 add array element", `{"foo":["bar","baz"]}`,
+            `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+            `{"foo":["bar","qux","baz"]}`},
+        {"A.3 remove object member", `{"baz":"qux","foo":"bar"}`,
+            `[{"op":"remove","path":"/baz"}]`,
+            `{"foo":"bar"}`},
+        {"A.4 remove array element", `{"foo":["bar","qux","baz"]}`,
+            `[{"op":"remove","path":"/foo/1"}]`,
+            `{"foo":["bar","baz"]}`},
+        {"A.5 replace value", `{"baz":"qux","foo":"bar"}`,
+            `[{"op":"replace","path":"/baz","value":"boo"}]`,
+            `{"baz":"boo","foo":"bar"}`},
+        {"A.6 move value", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+            `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+            `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+        {"A.7 move array element", `{"foo":["all","grass","cows","eat"]}`,
+            `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+            `{"foo":["all","cows","eat","grass"]}`},
+        {"A.8 test value", `{"baz":"qux","foo":["a",2,"c"]}`,
+            `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+            `{"baz":"qux","foo":["a",2,"c"]}`},
+        {"A.10 add nested member", `{"foo":"bar"}`,
+            `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+            `{"foo":"bar","child":{"grandchild":{}}}`},
+        {"A.11 ignore unrecognized elements", `{"foo":"bar"}`,
+            `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+            `{"foo":"bar","baz":"qux"}`},
+        {"A.14 escape ordering", `{"/":9,"~1":10}`,
+            `[{"op":"test","path":"/~01","value":10}]`,
+            `{"/":9,"~1":10}`},
+        {"A.16 add array value", `{"foo":["bar"]}`,
+            `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+            `{"foo":["bar",["abc","def"]]}`},
+
+        {"add with escaped keys", `{}`,
+            `[{"op":"add","path":"/a~1b","value":1},{"op":"add","path":"/m~0n","value":2}]`,
+            `{"a/b":1,"m~n":2}`},
+        {"add to the end of a nested array", `{"a":{"b":[1]}}`,
+            `[{"op":"add","path":"/a/b/-","value":2},{"op":"add","path":"/a/b/-","value":3}]`,
+            `{"a":{"b":[1,2,3]}}`},
+        {"move into a sibling", `{"a":{"b":1},"c":{}}`,
+            `[{"op":"move","from":"/a","path":"/c/a"}]`,
+            `{"c":{"a":{"b":1}}}`},
+        {"move to itself", `{"a":1}`,
+            `[{"op":"move","from":"/a","path":"/a"}]`,
+            `{"a":1}`},
+        {"copy is independent", `{"a":{"b":1}}`,
+            `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`,
+            `{"a":{"b":1},"c":{"b":2}}`},
+        {"replace whole document", `{"a":1}`,
+            `[{"op":"replace","path":"","value":{"b":2}}]`,
+            `{"b":2}`},
+        {"empty patch", `{"a":1}`, `[]`, `{"a":1}`},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+            if err != nil {
+                t.Fatalf("ApplyJSONPatch: %v", err)
+            }
+            assertJSONEqual(t, got, tt.want)
+        })
+    }
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+    tests := []struct {
+        name  string
+        doc   string
+        patch string
+        want  error
+    }{
+        // RFC 6902, Appendix A.
+        {"A.9 test error", `{"baz":"qux","foo":["a",2,"c"]}`,
+            `[{"op":"test","path":"/baz","value":"bar"}]`, ErrPatchTestFailed},
+        {"A.12 add to a nonexistent target", `{"foo":"bar"}`,
+            `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ErrInvalidPatch},
+        {"A.13 invalid patch document", `{"foo":"bar"}`,
+            `[{"op":"add","path":"/baz","value":"qux","op":"remove"}]`, ErrInvalidPatch},
+        {"A.15 compare strings and numbers", `{"/":9,"~1":10}`,
+            `[{"op":"test","path":"/~01","value":"10"}]`, ErrPatchTestFailed},
+
+        {"failing test after a change", `{"a":1}`,
+            `[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`, ErrPatchTestFailed},
+        {"test of a missing member", `{"a":1}`,
+            `[{"op":"test","path":"/b","value":1}]`, ErrInvalidPatch},
+        {"move into a child", `{"a":{"b":1}}`,
+            `[{"op":"move","from":"/a","path":"/a/c"}]`, ErrInvalidPatch},
+        {"end index outside add", `{"a":[1]}`,
+            `[{"op":"replace","path":"/a/-","value":2}]`, ErrInvalidPatch},
+        {"remove at the end index", `{"a":[1]}`,
+            `[{"op":"remove","path":"/a/-"}]`, ErrInvalidPatch},
+        {"array index with a leading zero", `{"a":[1,2]}`,
+            `[{"op":"remove","path":"/a/01"}]`, ErrInvalidPatch},
+        {"array index out of range", `{"a":[1]}`,
+            `[{"op":"add","path":"/a/2","value":2}]`, ErrInvalidPatch},
+        {"replace a missing member", `{"a":1}`,
+            `[{"op":"replace","path":"/b","value":2}]`, ErrInvalidPatch},
+        {"remove the whole document", `{"a":1}`,
+            `[{"op":"remove","path":""}]`, ErrInvalidPatch},
+        {"pointer without a leading slash", `{"a":1}`,
+            `[{"op":"remove","path":"a"}]`, ErrInvalidPatch},
+        {"missing path", `{"a":1}`,
+            `[{"op":"remove"}]`, ErrInvalidPatch},
+        {"missing value", `{"a":1}`,
+            `[{"op":"add","path":"/b"}]`, ErrInvalidPatch},
+        {"missing from", `{"a":1}`,
+            `[{"op":"copy","path":"/b"}]`, ErrInvalidPatch},
+        {"unknown operation", `{"a":1}`,
+            `[{"op":"merge","path":"/a","value":1}]`, ErrInvalidPatch},
+        {"not an array", `{"a":1}`,
+            `{"op":"remove","path":"/a"}`, ErrInvalidPatch},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+            if !errors.Is(err, tt.want) {
+                t.Errorf("err = %v, want %v", err, tt.want)
+            }
+        })
+    }
+}
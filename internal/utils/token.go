@@ -0,0 +1,21 @@
+package utils
+
+import (
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+)
+
+func GenerateRandomToken(size int) (string, error) {
+    bytes := make([]byte, size)
+    if _, err := rand.Read(bytes); err != nil {
+        return "", err
+    }
+    return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func HashToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
+}
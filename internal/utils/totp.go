@@ -0,0 +1,101 @@
+package utils
+
+import (
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha1"
+    "crypto/subtle"
+    "encoding/base32"
+    "encoding/binary"
+    "fmt"
+    "net/url"
+    "strings"
+    "time"
+)
+
+const (
+    TOTPDigits = 6
+    TOTPPeriod = 30
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateTOTPSecret() (string, error) {
+    bytes := make([]byte, 20)
+    if _, err := rand.Read(bytes); err != nil {
+        return "", err
+    }
+    return totpEncoding.EncodeToString(bytes), nil
+}
+
+func TOTPURI(issuer, account, secret string) string {
+    label := url.PathEscape(issuer + ":" + account)
+
+    params := url.Values{}
+    params.Set("secret", secret)
+    params.Set("issuer", issuer)
+    params.Set("algorithm", "SHA1")
+    params.Set("digits", fmt.Sprint(TOTPDigits))
+    params.Set("period", fmt.Sprint(TOTPPeriod))
+
+    return "otpauth://totp/" + label + "?" + strings.ReplaceAll(params.Encode(), "+", "%20")
+}
+
+func TOTPStep(t time.Time) int64 {
+    return t.Unix() / TOTPPeriod
+}
+
+func TOTPCode(secret string, step int64) (string, error) {
+    key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+    if err != nil {
+        return "", err
+    }
+
+    var counter [8]byte
+    binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+    mac := hmac.New(sha1.New, key)
+    mac.Write(counter[:])
+    sum := mac.Sum(nil)
+
+    offset := sum[len(sum)-1] & 0x0f
+    value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+    return fmt.Sprintf("%0*d", TOTPDigits, value%1000000), nil
+}
+
+// ValidateTOTP checks the code against the current step and skew steps on
+// either side of it and returns the matched step.
+func ValidateTOTP(secret, code string, t time.Time, skew int64) (int64, bool) {
+    current := TOTPStep(t)
+    for step := current - skew; step <= current+skew; step++ {
+        expected, err := TOTPCode(secret, step)
+        if err != nil {
+            return 0, false
+        }
+        if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+            return step, true
+        }
+    }
+    return 0, false
+}
+
+func GenerateRecoveryCode() (string, error) {
+    bytes := make([]byte, 10)
+    if _, err := rand.Read(bytes); err != nil {
+        return "", err
+    }
+
+    code := strings.ToLower(totpEncoding.EncodeToString(bytes))
+    return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16], nil
+}
+
+func NormalizeRecoveryCode(code string) string {
+    code = strings.ToLower(strings.TrimSpace(code))
+    code = strings.ReplaceAll(code, "-", "")
+    code = strings.ReplaceAll(code, " ", "")
+    if len(code) != 16 {
+        return code
+    }
+    return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16]
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+    "testing"
+    "time"
+)
+
+// rfc6238Secret is the SHA-1 seed of RFC 6238, "12345678901234567890", in
+// base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The expected codes are the last six digits of the RFC 6238, Appendix B
+// SHA-1 test vectors.
+var rfc6238Vectors = []struct {
+    unix int64
+    code string
+}{
+    {59, "287082"},
+    {1111111109, "081804"},
+    {1111111111, "050471"},
+    {1234567890, "005924"},
+    {2000000000, "279037"},
+    {20000000000, "353130"},
+}
+
+func TestTOTPCode(t *testing.T) {
+    for _, v := range rfc6238Vectors {
+        got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(v.unix, 0)))
+        if err != nil {
+            t.Fatalf("TOTPCode at %d: %v", v.unix, err)
+        }
+        if got != v.code {
+            t.Errorf("TOTPCode at %d = %s, want %s", v.unix, got, v.code)
+        }
+    }
+
+    lower, err := TOTPCode("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", TOTPStep(time.Unix(59, 0)))
+    if err != nil || lower != "287082" {
+        t.Errorf("TOTPCode with a lower-case secret = %q, %v", lower, err)
+    }
+
+    if _, err := TOTPCode("not base32!", 1); err == nil {
+        t.Error("TOTPCode accepted an invalid secret")
+    }
+}
+
+func TestValidateTOTP(t *testing.T) {
+    now := time.Unix(1111111111, 0)
+    current := TOTPStep(now)
+
+    code := func(step int64) string {
+        c, err := TOTPCode(rfc6238Secret, step)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return c
+    }
+
+    tests := []struct {
+        name     string
+        code     string
+        skew     int64
+        wantStep int64
+        wantOK   bool
+    }{
+        {"RFC vector", "050471", 0, current, true},
+        {"previous step within skew", code(current - 1), 1, current - 1, true},
+        {"next step within skew", code(current + 1), 1, current + 1, true},
+        {"previous step without skew", code(current - 1), 0, 0, false},
+        {"outside skew", code(current - 2), 1, 0, false},
+        {"wrong code", "000000", 1, 0, false},
+        {"short code", "50471", 1, 0, false},
+        {"empty code", "", 1, 0, false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            step, ok := ValidateTOTP(rfc6238Secret, tt.code, now, tt.skew)
+            if ok != tt.wantOK || step != tt.wantStep {
+                t.Errorf("ValidateTOTP = %d, %t, want %d, %t", step, ok, tt.wantStep, tt.wantOK)
+            }
+        })
+    }
+
+    if _, ok := ValidateTOTP("not base32!", "050471", now, 1); ok {
+        t.Error("ValidateTOTP accepted a code for an invalid secret")
+    }
+}
@@ -1,11 +1,93 @@
 package utils
 
 import (
+    "reflect"
+    "strings"
+    "unicode"
+
     "github.com/go-playground/validator/v10"
+    "github.com/MorozkoArt/go-crud-api/internal/apperrors"
+)
+
+const (
+    PasswordMinLength = 8
+    PasswordMaxLength = 72
 )
 
-var validate = validator.New()
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+    v := validator.New()
+    v.RegisterValidation("password", validatePassword)
+    v.RegisterTagNameFunc(jsonFieldName)
+    return v
+}
+
+// jsonFieldName makes validation errors report fields by their JSON names.
+func jsonFieldName(field reflect.StructField) string {
+    name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+    if name == "-" {
+        return ""
+    }
+    return name
+}
+
+// Validator exposes the shared validator so translations can be registered on it.
+func Validator() *validator.Validate {
+    return validate
+}
 
 func ValidateStruct(s interface{}) error {
-    return validate.Struct(s)
-}
\ No newline at end of file
+    if err := validate.Struct(s); err != nil {
+        return apperrors.Wrap(apperrors.ErrValidation, err)
+    }
+    return nil
+}
+
+func validatePassword(fl validator.FieldLevel) bool {
+    password := fl.Field().String()
+    if len(password) < PasswordMinLength || len(password) > PasswordMaxLength {
+        return false
+    }
+
+    var hasLetter, hasDigit bool
+    for _, r := range password {
+        switch {
+        case unicode.IsLetter(r):
+            hasLetter = true
+        case unicode.IsDigit(r):
+            hasDigit = true
+        }
+    }
+
+    return hasLetter && hasDigit
+}
+
+// ValidationParam returns the rule parameter, converting references to
+// other struct fields into their JSON names.
+func ValidationParam(fe validator.FieldError) string {
+    switch fe.Tag() {
+    case "eqfield", "nefield", "required_with", "required_without":
+        return snakeCase(fe.Param())
+    }
+    return fe.Param()
+}
+
+// snakeCase converts a Go field name such as RecoveryCode or MFAToken to
+// the snake_case form used by the JSON tags in models.
+func snakeCase(name string) string {
+    runes := []rune(name)
+
+    var b strings.Builder
+    for i, r := range runes {
+        if unicode.IsUpper(r) && i > 0 {
+            prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+            nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+            if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+                b.WriteByte('_')
+            }
+        }
+        b.WriteRune(unicode.ToLower(r))
+    }
+    return b.String()
+}